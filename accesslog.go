@@ -0,0 +1,196 @@
+package log
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDKey is the field name of the request ID
+const RequestIDKey = "request_id"
+
+// DefaultAccessLogLevels is the log level of each status class, i.e. 4 for the
+// 4xx statuses
+var DefaultAccessLogLevels = map[int]logrus.Level{
+	1: InfoLevel,
+	2: InfoLevel,
+	3: InfoLevel,
+	4: WarnLevel,
+	5: ErrorLevel,
+}
+
+// DefaultAccessLogSkipPaths are the health check paths without access log
+var DefaultAccessLogSkipPaths = []string{"/healthz", "/livez", "/readyz"}
+
+// AccessLogConfig configures the access log middleware
+type AccessLogConfig struct {
+	// Levels overrides the log level of some status classes, see
+	// DefaultAccessLogLevels
+	Levels map[int]logrus.Level
+	// SkipPaths are the request paths without access log,
+	// DefaultAccessLogSkipPaths if nil
+	SkipPaths []string
+	// RequestIDHeader is the header of the request ID, `X-Request-Id` by
+	// default. A random request ID is generated if the request has none, and
+	// it is set in the response header.
+	RequestIDHeader string
+}
+
+// AccessLog returns a middleware logging every request with the `method`,
+// `path`, `status`, `bytes`, `duration_ms`, `remote_addr` and `request_id`
+// fields. The handlers get the request-scoped logger with the `request_id`,
+// `method` and `path` fields by FromContext, and the entries have the calling
+// info of AccessLog.
+func (logger *SWLog) AccessLog(config AccessLogConfig) func(http.Handler) http.Handler {
+	filename, funcname := caller(logger.skip)
+	return logger.accessLog(config, filename, funcname)
+}
+
+func (logger *SWLog) accessLog(config AccessLogConfig, filename string, funcname string) func(http.Handler) http.Handler {
+	levels := make(map[int]logrus.Level, len(DefaultAccessLogLevels))
+	for class, level := range DefaultAccessLogLevels {
+		levels[class] = level
+	}
+	for class, level := range config.Levels {
+		levels[class] = level
+	}
+	skipPaths := config.SkipPaths
+	if skipPaths == nil {
+		skipPaths = DefaultAccessLogSkipPaths
+	}
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+	header := config.RequestIDHeader
+	if header == "" {
+		header = "X-Request-Id"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(header)
+			if requestID == "" {
+				requestID = newRequestID()
+			}
+			w.Header().Set(header, requestID)
+			ctx := logger.NewContext(r.Context(), logrus.Fields{
+				RequestIDKey: requestID,
+				"method":     r.Method,
+				"path":       r.URL.Path,
+			})
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			start := logger.Clock().Now()
+			rw := &responseWriter{ResponseWriter: w}
+			// the panicking requests are logged as well, net/http recovers
+			// them and aborts the response
+			defer func() {
+				recovered := recover()
+				status := rw.status
+				if recovered != nil {
+					status = http.StatusInternalServerError
+				} else if status == 0 {
+					status = http.StatusOK
+				}
+
+				level, ok := levels[status/100]
+				if !ok {
+					level = InfoLevel
+				}
+				logger.FromContext(ctx).WithFields(logrus.Fields{
+					"status":      status,
+					"bytes":       rw.bytes,
+					"duration_ms": float64(logger.Clock().Now().Sub(start)) / float64(time.Millisecond),
+					"remote_addr": r.RemoteAddr,
+				}).Log(level, filename, funcname, fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, status))
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestIDs counts the request IDs which are not random
+var requestIDs atomic.Uint64
+
+// newRequestID returns a random request ID, or the time and a counter if the
+// random source fails
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x-%x", time.Now().UnixNano(), requestIDs.Add(1))
+	}
+	return hex.EncodeToString(b)
+}
+
+// responseWriter records the status and the size of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// the informational headers are followed by the final one
+	if w.status == 0 && status >= http.StatusOK {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush flushes the response if the underlying writer supports it
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection if the underlying writer supports it, i.e.
+// for websockets
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// AccessLog returns a middleware logging every request into SWLogger, see
+// SWLog.AccessLog
+func AccessLog(config AccessLogConfig) func(http.Handler) http.Handler {
+	filename, funcname := caller(SWLogger.skip)
+	return SWLogger.accessLog(config, filename, funcname)
+}
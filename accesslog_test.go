@@ -0,0 +1,139 @@
+package log_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	logger, err := log.New(
+		log.WithOutput(&buf),
+		log.WithFormatter(log.OutputFile, &log.LogfmtFormatter{}),
+		log.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	middleware := logger.AccessLog(log.AccessLogConfig{Levels: map[int]logrus.Level{4: log.InfoLevel}})
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Info("handling")
+		clock.Add(1500 * time.Microsecond)
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte("hello"))
+		}
+	}))
+
+	for _, c := range []struct {
+		path      string
+		requestID string
+		want      []string
+	}{
+		{"/hello", "req-1", []string{
+			"level=info ", "/accesslog_test.go:28 ", "func=TestAccessLog ", `msg="GET /hello 200" `, "bytes=5 ",
+			"duration_ms=1.5 ", "method=GET ", "path=/hello ", "remote_addr=192.0.2.1:1234 ", "request_id=req-1 ", "status=200 ",
+		}},
+		{"/missing", "req-2", []string{`level=info `, `msg="GET /missing 404" `, "bytes=19 ", "status=404 "}},
+		{"/fail", "", []string{`level=error `, `msg="GET /fail 502" `, "bytes=0 ", "status=502 "}},
+		{"/healthz", "req-4", nil},
+	} {
+		buf.Reset()
+		r := httptest.NewRequest(http.MethodGet, c.path, nil)
+		if c.requestID != "" {
+			r.Header.Set("X-Request-Id", c.requestID)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		requestID := w.Header().Get("X-Request-Id")
+		if c.requestID != "" && requestID != c.requestID || len(requestID) == 0 {
+			t.Errorf("%s: got request ID %q, want %q", c.path, requestID, c.requestID)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		// the handler logs with the request-scoped logger
+		if !strings.Contains(lines[0], "msg=handling method=GET path="+c.path+" request_id="+requestID) {
+			t.Errorf("%s: unexpected handler line %q", c.path, lines[0])
+		}
+		if c.want == nil {
+			if len(lines) != 1 {
+				t.Errorf("%s: got access log %q", c.path, lines[1:])
+			}
+			continue
+		}
+		if len(lines) != 2 {
+			t.Fatalf("%s: got %d lines, want 2: %q", c.path, len(lines), buf.String())
+		}
+		for _, field := range c.want {
+			if !strings.Contains(lines[1]+" ", field) {
+				t.Errorf("%s: %q is missing in %q", c.path, field, lines[1])
+			}
+		}
+	}
+}
+
+func TestResponseWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := log.New(log.WithOutput(&buf), log.WithFormatter(log.OutputFile, &log.LogfmtFormatter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := logger.AccessLog(log.AccessLogConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Error(err)
+		}
+		if _, _, err := http.NewResponseController(w).Hijack(); err == nil {
+			t.Error("hijacked the recorder")
+		}
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/items", nil))
+	if !recorder.Flushed {
+		t.Error("the response is not flushed")
+	}
+	if line := buf.String(); !strings.Contains(line, "bytes=7 ") || !strings.Contains(line, "status=201\n") {
+		t.Errorf("unexpected access log %q", line)
+	}
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := log.New(log.WithOutput(&buf), log.WithFormatter(log.OutputFile, &log.LogfmtFormatter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := logger.AccessLog(log.AccessLogConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	// the panic goes on to net/http once logged
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the panic of the handler", r)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/crash", nil))
+	}()
+	if line := buf.String(); !strings.Contains(line, "level=error ") || !strings.Contains(line, `msg="GET /crash 500" `) ||
+		!strings.Contains(line, "status=500\n") {
+		t.Errorf("unexpected access log %q", line)
+	}
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OverflowPolicy decides what to do with an entry when the queue of the async
+// mode is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the logging call until the queue has room
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the entry being logged
+	OverflowDropNewest
+	// OverflowDropDebugInfo drops the DEBUG and INFO entries being logged,
+	// and blocks for WARNING and above
+	OverflowDropDebugInfo
+)
+
+// asyncQueue is the bounded queue of the async mode, whose records are
+// written by a single goroutine.
+type asyncQueue struct {
+	logger  *SWLog
+	policy  OverflowPolicy
+	records chan *record
+	// dropped entries counters indexed by level
+	dropped [logrus.TraceLevel + 1]atomic.Uint64
+	// mu guards records from being closed while sending
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// newAsyncQueue creates the queue and starts the writing goroutine
+func newAsyncQueue(logger *SWLog, size int, policy OverflowPolicy) *asyncQueue {
+	q := &asyncQueue{
+		logger:  logger,
+		policy:  policy,
+		records: make(chan *record, size),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for r := range q.records {
+		if r.flushed != nil {
+			close(r.flushed)
+			continue
+		}
+		q.logger.write(r)
+	}
+}
+
+// enqueue queues the record or drops it per the overflow policy, it returns
+// false if the record must be written synchronously by the caller. Panic and
+// fatal records are never queued, the queue is flushed before them instead.
+func (q *asyncQueue) enqueue(r *record) bool {
+	if r.level <= logrus.FatalLevel {
+		q.flush()
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	block := q.policy == OverflowBlock ||
+		(q.policy == OverflowDropDebugInfo && r.level < logrus.InfoLevel)
+	if block {
+		q.records <- r
+		return true
+	}
+	select {
+	case q.records <- r:
+	default:
+		q.dropped[r.level].Add(1)
+	}
+	return true
+}
+
+// flush waits until the records queued before are written
+func (q *asyncQueue) flush() {
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	q.records <- &record{flushed: flushed}
+	q.mu.RUnlock()
+	<-flushed
+}
+
+// close writes the queued records and stops the writing goroutine
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.records)
+	}
+	q.mu.Unlock()
+	<-q.done
+}
+
+// Flush waits until the entries logged before in async mode are written, it
+// does nothing in sync mode.
+func (logger *SWLog) Flush() {
+	if logger.async != nil {
+		logger.async.flush()
+	}
+}
+
+// Dropped returns the number of entries of the given level dropped by the
+// overflow policy of the async mode.
+func (logger *SWLog) Dropped(level logrus.Level) uint64 {
+	if logger.async == nil || int(level) >= len(logger.async.dropped) {
+		return 0
+	}
+	return logger.async.dropped[level].Load()
+}
+
+// DroppedTotal returns the number of entries of all levels dropped by the
+// overflow policy of the async mode.
+func (logger *SWLog) DroppedTotal() uint64 {
+	var total uint64
+	for _, level := range logrus.AllLevels {
+		total += logger.Dropped(level)
+	}
+	return total
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gateWriter blocks every write until it is opened
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	open    chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}, 100), open: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.open
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func newAsyncTestLogger(t *testing.T, w *gateWriter, size int, policy OverflowPolicy) *SWLog {
+	logger, err := New(
+		WithLevel(logrus.DebugLevel),
+		WithOutput(w),
+		WithFormatter(OutputFile, &Formatter{LogFormat: "%msg%\n"}),
+		WithAsync(size, policy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	w := newGateWriter()
+	logger := newAsyncTestLogger(t, w, 2, OverflowDropNewest)
+
+	logger.Info("a")
+	// wait for the writing goroutine being blocked by "a"
+	<-w.entered
+	logger.Info("b")
+	logger.Warn("c")
+	logger.Info("d")
+	logger.Error("e")
+
+	close(w.open)
+	logger.Flush()
+	if got := w.String(); got != "a\nb\nc\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if logger.Dropped(logrus.InfoLevel) != 1 || logger.Dropped(logrus.ErrorLevel) != 1 || logger.DroppedTotal() != 2 {
+		t.Fatalf("unexpected dropped counters info=%d error=%d total=%d",
+			logger.Dropped(logrus.InfoLevel), logger.Dropped(logrus.ErrorLevel), logger.DroppedTotal())
+	}
+}
+
+func TestAsyncDropDebugInfo(t *testing.T) {
+	w := newGateWriter()
+	logger := newAsyncTestLogger(t, w, 1, OverflowDropDebugInfo)
+
+	logger.Info("a")
+	<-w.entered
+	logger.Warn("b")
+	logger.Debug("c")
+	logger.Info("d")
+
+	// the queue is full, the error waits for room instead of being dropped
+	logged := make(chan struct{})
+	go func() {
+		logger.Error("e")
+		close(logged)
+	}()
+	select {
+	case <-logged:
+		t.Fatal("the error did not wait for the full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.open)
+	<-logged
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.String(); got != "a\nb\ne\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if logger.Dropped(logrus.DebugLevel) != 1 || logger.Dropped(logrus.InfoLevel) != 1 ||
+		logger.Dropped(logrus.ErrorLevel) != 0 {
+		t.Fatalf("unexpected dropped counters debug=%d info=%d error=%d", logger.Dropped(logrus.DebugLevel),
+			logger.Dropped(logrus.InfoLevel), logger.Dropped(logrus.ErrorLevel))
+	}
+}
+
+func TestAsyncClose(t *testing.T) {
+	w := newGateWriter()
+	close(w.open)
+	logger := newAsyncTestLogger(t, w, 100, OverflowBlock)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.Info("x")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := bytes.Count([]byte(w.String()), []byte("x\n")); got != 100 {
+		t.Fatalf("got %d entries, want 100", got)
+	}
+
+	// the entries logged after closing are written synchronously
+	logger.Info("after close")
+	if got := w.String(); !bytes.HasSuffix([]byte(got), []byte("after close\n")) {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if logger.DroppedTotal() != 0 {
+		t.Fatalf("unexpected dropped entries %d", logger.DroppedTotal())
+	}
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// callerKey is the context key of the calling info carried by a logrus.Entry
+type callerKey struct{}
+
+// callerInfo is the calling info of a single log call
+type callerInfo struct {
+	// file name and line number where calling the LOG/INFO/DEBUG...
+	fileName string
+	// function name where calling the LOG/INFO/DEBUG...
+	funcName string
+}
+
+// caller returns the file name with line number and the function name of the
+// stack frame which is skip frames above the function calling caller.
+func caller(skip int) (filename string, funcname string) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", ""
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		funcname = fn.Name()
+	}
+	return callerNames(file, line, funcname)
+}
+
+// callerNames returns the file name with line number and the function name of
+// the calling info as they are logged
+func callerNames(file string, line int, function string) (filename string, funcname string) {
+	funcname = filepath.Ext(function)            // main.(*MyStruct).foo => .foo
+	funcname = strings.TrimPrefix(funcname, ".") // foo
+
+	dir, file := filepath.Split(file)
+	filename = filepath.Base(dir) + "/" + filepath.Base(file) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
+	return filename, funcname
+}
+
+// withCaller returns a copy of ctx carrying the calling info
+func withCaller(ctx context.Context, filename string, funcname string) context.Context {
+	return context.WithValue(ctx, callerKey{}, callerInfo{fileName: filename, funcName: funcname})
+}
+
+// EntryCaller returns the file name with line number and the function name
+// where the entry was logged, it is meant to be used by formatters.
+func EntryCaller(entry *logrus.Entry) (filename string, funcname string) {
+	if entry.Context == nil {
+		return "", ""
+	}
+	info, _ := entry.Context.Value(callerKey{}).(callerInfo)
+	return info.fileName, info.funcName
+}
+
+// Caller returns the file name with line number and the function name as they
+// are logged of the stack frame which is skip frames above the function calling
+// Caller, i.e. for the wrappers logging with SWLog.Log.
+func Caller(skip int) (filename string, funcname string) {
+	return caller(skip + 1)
+}
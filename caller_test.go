@@ -0,0 +1,73 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func logFromAlpha(logger *SWLog) {
+	logger.Info("alpha")
+}
+
+func logFromBeta(logger *SWLog) {
+	logger.Infof("%s", "beta")
+}
+
+func TestCallerConcurrent(t *testing.T) {
+	logger := &SWLog{}
+	logger.Init(filepath.Join(t.TempDir(), "caller.log"), logrus.DebugLevel, false)
+	var buf bytes.Buffer
+	logger.FileLogger.SetOutput(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i%2 == 0 {
+					logFromAlpha(logger)
+				} else {
+					logFromBeta(logger)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 100*50 {
+		t.Fatalf("got %d lines, want %d", len(lines), 100*50)
+	}
+	for _, line := range lines {
+		fields := strings.Split(line, "|")
+		if len(fields) != 4 {
+			t.Fatalf("malformed line %q", line)
+		}
+		funcname, site := "logFromAlpha(", "/caller_test.go:15)"
+		if fields[3] == "beta" {
+			funcname, site = "logFromBeta(", "/caller_test.go:19)"
+		}
+		if got := fields[2]; !strings.HasPrefix(got, funcname) || !strings.HasSuffix(got, site) {
+			t.Fatalf("line %q has caller %q, want %s...%s", line, got, funcname, site)
+		}
+	}
+}
+
+func TestEntryCaller(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	if filename, funcname := EntryCaller(entry); filename != "" || funcname != "" {
+		t.Fatalf("EntryCaller of a bare entry = %q, %q", filename, funcname)
+	}
+
+	entry = entry.WithContext(withCaller(context.Background(), "log/caller_test.go:1", "TestEntryCaller"))
+	if filename, funcname := EntryCaller(entry); filename != "log/caller_test.go:1" || funcname != "TestEntryCaller" {
+		t.Fatalf("EntryCaller = %q, %q", filename, funcname)
+	}
+}
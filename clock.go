@@ -0,0 +1,25 @@
+package log
+
+import "time"
+
+// Clock is the source of the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock of the local system time
+type systemClock struct{}
+
+// Now returns the current local time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Clock returns the time source of the entries, i.e. to measure durations
+// logged as fields, the local system time by default
+func (logger *SWLog) Clock() Clock {
+	if logger.clock == nil {
+		return systemClock{}
+	}
+	return logger.clock
+}
@@ -1,12 +1,106 @@
 package log
 
-import "github.com/fatih/color"
+import (
+	"io"
+	"os"
 
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/sirupsen/logrus"
+)
+
+// ColorFormat is the color of a part of the log line, zero attributes are
+// ignored.
 type ColorFormat struct {
 	Foreground color.Attribute
 	Background color.Attribute
 	Actions    color.Attribute
 }
 
+// ColorPart selects the parts of the log line to be colored
+type ColorPart int
+
+const (
+	// ColorLevel colors the level column
+	ColorLevel ColorPart = 1 << iota
+	// ColorCaller colors the function name and the file name
+	ColorCaller
+	// ColorMessage colors the log message
+	ColorMessage
+)
+
+var (
+	// DefaultColors is the color of each log level used by stderr output
+	DefaultColors = map[logrus.Level]ColorFormat{
+		PanicLevel: {Foreground: color.FgHiWhite, Background: color.BgRed, Actions: color.Bold},
+		FatalLevel: {Foreground: color.FgHiRed, Actions: color.Bold},
+		ErrorLevel: {Foreground: color.FgRed},
+		WarnLevel:  {Foreground: color.FgYellow},
+		InfoLevel:  {Foreground: color.FgGreen},
+		DebugLevel: {Foreground: color.FgCyan},
+	}
+)
+
+// Sprint returns s wrapped by the escape codes of the color format
+func (c ColorFormat) Sprint(s string) string {
+	var attrs []color.Attribute
+	for _, attr := range []color.Attribute{c.Actions, c.Foreground, c.Background} {
+		if attr != 0 {
+			attrs = append(attrs, attr)
+		}
+	}
+	if len(attrs) == 0 {
+		return s
+	}
+
+	// the terminal detection is up to SWLog rather than color.NoColor which
+	// only looks at stdout
+	format := color.New(attrs...)
+	format.EnableColor()
+	return format.Sprint(s)
+}
+
+// IsColorTerminal reports whether w is a terminal which supports colors and
+// the `NO_COLOR` environment variable is not set.
+func IsColorTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// SetColors set the color of each log level and the parts of the stderr log
+// line to be colored. The stderr output is left uncolored if it is not a
+// terminal or `NO_COLOR` is set, the log file is never colored.
+func (logger *SWLog) SetColors(colors map[logrus.Level]ColorFormat, parts ColorPart) {
+	logger.colors = colors
+	logger.colorParts = parts
+	// the colors are applied at setup
+	if logger.STDLogger == nil {
+		return
+	}
+	if !logger.isRaw {
+		logger.STDLogger.SetFormatter(logger.newSTDFormatter())
+	}
+}
+
+// newSTDFormatter returns the Formatter of the stderr output, which is colored
+// when possible
+func (logger *SWLog) newSTDFormatter() *Formatter {
+	formatter := &Formatter{}
+	if IsColorTerminal(logger.STDLogger.Out) {
+		formatter.Colors = logger.colors
+		formatter.ColorParts = logger.colorParts
+	}
+	return formatter
+}
+
+// SetupColor colors the level column of SWLogger stderr output with the
+// DefaultColors.
 func SetupColor() {
+	SWLogger.SetColors(DefaultColors, ColorLevel)
 }
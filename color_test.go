@@ -0,0 +1,105 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+)
+
+func TestFormatterColors(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).
+		WithContext(withCaller(context.Background(), "log/color_test.go:1", "TestFormatterColors"))
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "failed"
+
+	red := ColorFormat{Foreground: color.FgRed}
+	f := &Formatter{LogFormat: "%lvl%|%func%(%line%)|%msg%", Colors: map[logrus.Level]ColorFormat{logrus.ErrorLevel: red}}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[31mERROR  \x1b[0m|TestFormatterColors(log/color_test.go:1)|failed"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+
+	f.ColorParts = ColorCaller | ColorMessage
+	b, err = f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "ERROR  |\x1b[31mTestFormatterColors\x1b[0m(\x1b[31mlog/color_test.go:1\x1b[0m)|\x1b[31mfailed\x1b[0m"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+
+	// levels without color format are left uncolored
+	entry.Level = logrus.InfoLevel
+	b, err = f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "\x1b[") {
+		t.Fatalf("unexpected escape codes in %q", b)
+	}
+}
+
+func TestIsColorTerminal(t *testing.T) {
+	if IsColorTerminal(&bytes.Buffer{}) {
+		t.Error("a buffer is not a terminal")
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "color.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if IsColorTerminal(f) {
+		t.Error("a regular file is not a terminal")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if IsColorTerminal(os.Stderr) {
+		t.Error("NO_COLOR is set")
+	}
+}
+
+func TestSetColors(t *testing.T) {
+	logger := &SWLog{}
+	logger.Init(filepath.Join(t.TempDir(), "color.log"), logrus.DebugLevel, true)
+	var stdBuf, fileBuf bytes.Buffer
+	logger.STDLogger.SetOutput(&stdBuf)
+	logger.FileLogger.SetOutput(&fileBuf)
+
+	// stderr is replaced by a buffer, which is not a terminal
+	logger.SetColors(DefaultColors, ColorLevel|ColorMessage)
+	logger.Error("no colors")
+	if strings.Contains(stdBuf.String(), "\x1b[") || strings.Contains(fileBuf.String(), "\x1b[") {
+		t.Fatalf("unexpected escape codes in %q and %q", stdBuf.String(), fileBuf.String())
+	}
+	if f := logger.STDLogger.Formatter.(*Formatter); f.Colors != nil {
+		t.Fatalf("colors set for a non terminal output: %v", f.Colors)
+	}
+}
+
+func TestSetupColorBeforeInit(t *testing.T) {
+	previous := SWLogger
+	SWLogger = &SWLog{}
+	t.Cleanup(func() {
+		SWLogger = previous
+	})
+
+	// the colors are kept until the setup
+	SetupColor()
+	SWLogger.SetColors(DefaultColors, ColorLevel|ColorMessage)
+	SWLogger.Init(filepath.Join(t.TempDir(), "color.log"), logrus.DebugLevel, true)
+	defer SWLogger.Close()
+	if SWLogger.colorParts != ColorLevel|ColorMessage {
+		t.Fatalf("got color parts %d", SWLogger.colorParts)
+	}
+}
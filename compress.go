@@ -0,0 +1,275 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+)
+
+// compressSuffix is the suffix of the compressed rotated files
+const compressSuffix = ".gz"
+
+var (
+	// same as the conversion of rotatelogs from the file name pattern to the glob
+	globConversionRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`%[%+A-Za-z]`),
+		regexp.MustCompile(`\*+`),
+	}
+)
+
+// compressor implements rotatelogs.Handler, it compresses the rotated files
+// which are no longer written and purges the files beyond the retention
+// including the compressed ones.
+type compressor struct {
+	// linkName is the link to the current file
+	linkName string
+	// glob matches all the rotated files
+	glob     string
+	rotation RotationConfig
+	// serializes the compressions and purges
+	mu sync.Mutex
+
+	// running tracks the Handle goroutines for Close
+	running sync.WaitGroup
+	closeMu sync.Mutex
+	closed  bool
+}
+
+// newCompressor creates the compressor of the files named by pattern
+func newCompressor(pattern string, linkName string, rotation RotationConfig) *compressor {
+	glob := pattern
+	for _, re := range globConversionRegexps {
+		glob = re.ReplaceAllString(glob, "*")
+	}
+	return &compressor{
+		linkName: linkName,
+		glob:     glob,
+		rotation: rotation,
+	}
+}
+
+// Handle compresses the rotated files when the rotatelogs writer switches to
+// a new file, it is called in a new goroutine by rotatelogs.
+func (c *compressor) Handle(e rotatelogs.Event) {
+	if _, ok := e.(*rotatelogs.FileRotatedEvent); !ok || !c.start() {
+		return
+	}
+	defer c.running.Done()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.rotatedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "list rotated log files error: %s\n", err.Error())
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+	// the newest file is written, the current file of the event is not once
+	// the goroutines of the former rotations run after the latter ones
+	current := files[len(files)-1]
+	for i, file := range files {
+		// the files left by the previous runs are compressed as well
+		if file == current || strings.HasSuffix(file, compressSuffix) {
+			continue
+		}
+		if err := compressFile(file); err != nil {
+			fmt.Fprintf(os.Stderr, "compress log file %s error: %s\n", file, err.Error())
+			continue
+		}
+		files[i] = file + compressSuffix
+	}
+
+	c.purge(files, current)
+}
+
+// start tracks a Handle goroutine, it returns false once the compressor is
+// closed
+func (c *compressor) start() bool {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	if c.closed {
+		return false
+	}
+	c.running.Add(1)
+	return true
+}
+
+// Close waits for the running compressions, the files rotated later are
+// compressed by the next run
+func (c *compressor) Close() error {
+	c.closeMu.Lock()
+	c.closed = true
+	c.closeMu.Unlock()
+	c.running.Wait()
+	return nil
+}
+
+// rotatedFiles returns the rotated files from the oldest, both compressed and
+// not
+func (c *compressor) rotatedFiles() ([]string, error) {
+	matches, err := filepath.Glob(c.glob)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := filepath.Glob(c.glob + compressSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(matches)+len(compressed))
+	var files []string
+	for _, file := range append(matches, compressed...) {
+		// ignore the lock files and the link of rotatelogs
+		if seen[file] || file == c.linkName || strings.HasSuffix(file, "_lock") || strings.HasSuffix(file, "_symlink") {
+			continue
+		}
+		if fi, err := os.Lstat(file); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+	return sortRotatedFiles(files), nil
+}
+
+// purge removes the files beyond the retention except the current one, the
+// files are sorted from the oldest
+func (c *compressor) purge(files []string, current string) {
+	var toRemove []string
+	if c.rotation.MaxCount > 0 {
+		if uint(len(files)) > c.rotation.MaxCount {
+			toRemove = files[:len(files)-int(c.rotation.MaxCount)]
+		}
+	} else {
+		cutoff := c.rotation.Clock.Now().Add(-c.rotation.MaxAge)
+		for _, file := range files {
+			if fi, err := os.Stat(file); err == nil && fi.ModTime().Before(cutoff) {
+				toRemove = append(toRemove, file)
+			}
+		}
+	}
+
+	for _, file := range toRemove {
+		if file != current {
+			os.Remove(file)
+		}
+	}
+}
+
+// sortRotatedFiles sorts the existing files from the oldest by the modification
+// time, the files modified at the same time are sorted by rotateNameLess
+func sortRotatedFiles(files []string) []string {
+	mtimes := make(map[string]time.Time, len(files))
+	sorted := make([]string, 0, len(files))
+	for _, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			mtimes[file] = fi.ModTime()
+			sorted = append(sorted, file)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if mi, mj := mtimes[sorted[i]], mtimes[sorted[j]]; !mi.Equal(mj) {
+			return mi.Before(mj)
+		}
+		return rotateNameLess(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// rotateNameLess reports whether the rotated file a is older than b by the
+// names without the compress suffix, the digits are compared as numbers, i.e.
+// `x.log.2021-03-10` < `x.log.2021-03-10.2` < `x.log.2021-03-10.10.gz`
+func rotateNameLess(a, b string) bool {
+	a, b = strings.TrimSuffix(a, compressSuffix), strings.TrimSuffix(b, compressSuffix)
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitsPrefix(a), digitsPrefix(b)
+			// compare the numbers without the leading zeros
+			ta, tb := strings.TrimLeft(a[:na], "0"), strings.TrimLeft(b[:nb], "0")
+			if len(ta) != len(tb) {
+				return len(ta) < len(tb)
+			}
+			if ta != tb {
+				return ta < tb
+			}
+			a, b = a[na:], b[nb:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	// the generations are appended to the names
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitsPrefix returns the length of the leading digits of s
+func digitsPrefix(s string) int {
+	n := 0
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
+
+// compressFile gzips the file into `file.gz` and removes the file, the
+// modification time is kept for the max age purging.
+func compressFile(file string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	// the temporary file is hidden from the glob of the rotated files
+	tmp := filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+compressSuffix+".tmp")
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(file)
+	zw.ModTime = fi.ModTime()
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(tmp, fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, file+compressSuffix); err != nil {
+		return err
+	}
+	return os.Remove(file)
+}
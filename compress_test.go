@@ -0,0 +1,148 @@
+package log_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
+)
+
+func readGzipFile(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// waitFiles waits until the files matching the glob of the log directory are
+// exactly the given ones
+func waitFiles(t *testing.T, glob string, want ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := filepath.Glob(glob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		equal := len(got) == len(want)
+		for i := 0; equal && i < len(got); i++ {
+			equal = filepath.Base(got[i]) == want[i]
+		}
+		if equal {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got files %v, want %v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRotationCompress(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "compress.log")
+	// the file left by a previous run
+	if err := os.WriteFile(logFile+".2021030908.log", []byte("zero\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	logger, err := log.New(
+		log.WithLogFile(logFile),
+		log.WithFormatter(log.OutputFile, &log.Formatter{LogFormat: "%msg%\n"}),
+		log.WithRotation(log.RotationConfig{
+			RotationTime: time.Hour,
+			MaxCount:     3,
+			Pattern:      ".%Y%m%d%H.log",
+			Clock:        clock,
+			Compress:     true,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFiles(t, logFile+".*", "compress.log.2021030908.log.gz", "compress.log.2021030910.log")
+
+	logger.Info("first")
+	clock.Add(time.Hour)
+	logger.Info("second")
+	waitFiles(t, logFile+".*",
+		"compress.log.2021030908.log.gz", "compress.log.2021030910.log.gz", "compress.log.2021030911.log")
+	if got := readGzipFile(t, logFile+".2021030910.log.gz"); got != "first\n" {
+		t.Errorf("unexpected compressed content %q", got)
+	}
+
+	// the oldest compressed file is beyond the max count
+	clock.Add(time.Hour)
+	logger.Info("third")
+	waitFiles(t, logFile+".*",
+		"compress.log.2021030910.log.gz", "compress.log.2021030911.log.gz", "compress.log.2021030912.log")
+	if got := readGzipFile(t, logFile+".2021030911.log.gz"); got != "second\n" {
+		t.Errorf("unexpected compressed content %q", got)
+	}
+	if got := log.ReadLogFile(t, logFile); got != "third\n" {
+		t.Errorf("unexpected current file content %q", got)
+	}
+}
+
+func TestRotationCompressGenerations(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "x.log")
+	logger, err := log.New(
+		log.WithLogFile(logFile),
+		log.WithFormatter(log.OutputFile, &log.Formatter{LogFormat: "%msg%\n"}),
+		log.WithClock(logtest.NewFakeClock(time.Date(2021, 3, 10, 10, 16, 18, 0, time.UTC))),
+		// the glob of the default pattern matches the compressed files as well
+		log.WithRotation(log.RotationConfig{MaxSize: 5, MaxCount: 2, Compress: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generations beyond 9 are sorted after the others
+	for i := 0; i < 12; i++ {
+		logger.Infof("gen%d", i)
+	}
+	waitFiles(t, logFile+".*", "x.log.2021-03-10.10.gz", "x.log.2021-03-10.11")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readGzipFile(t, logFile+".2021-03-10.10.gz"); got != "gen10\n" {
+		t.Errorf("unexpected compressed content %q", got)
+	}
+	if got := log.ReadLogFile(t, logFile); got != "gen11\n" {
+		t.Errorf("unexpected current file content %q", got)
+	}
+}
+
+func TestRotateNameLess(t *testing.T) {
+	names := []string{
+		"x.log.2021-03-09.1.gz",
+		"x.log.2021-03-10",
+		"x.log.2021-03-10.1.gz",
+		"x.log.2021-03-10.2",
+		"x.log.2021-03-10.10.gz",
+	}
+	for i := range names {
+		for j := range names {
+			if got := log.RotateNameLess(names[i], names[j]); got != (i < j) {
+				t.Errorf("rotateNameLess(%q, %q) = %v", names[i], names[j], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package log
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ContextExtractor returns the fields of the values carried by the context,
+// i.e. the request ID or the tenant, it may return nil.
+type ContextExtractor func(ctx context.Context) logrus.Fields
+
+// entryKey is the context key of the request-scoped logger
+type entryKey struct{}
+
+// NewContext returns a copy of ctx carrying the request-scoped logger of ctx
+// with the fields added, the logger is SWLogger if ctx carries none.
+func NewContext(ctx context.Context, fields logrus.Fields) context.Context {
+	return FromContext(ctx).WithFields(fields).NewContext(ctx)
+}
+
+// NewContext returns a copy of ctx carrying the request-scoped logger logging
+// into logger with the fields of ctx and the given fields
+func (logger *SWLog) NewContext(ctx context.Context, fields logrus.Fields) context.Context {
+	return logger.FromContext(ctx).WithFields(fields).NewContext(ctx)
+}
+
+// NewContext returns a copy of ctx carrying the entry as the request-scoped
+// logger
+func (entry *Entry) NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, entryKey{}, &Entry{logger: entry.logger, Data: entry.Data})
+}
+
+// FromContext returns the request-scoped logger of ctx, SWLogger without fields
+// if ctx carries none. The fields of the context values registered by
+// WithContextKey and WithContextExtractor are added when logging.
+func FromContext(ctx context.Context) *Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return &Entry{logger: SWLogger, ctx: ctx}
+}
+
+// FromContext returns the request-scoped logger of ctx logging into logger
+func (logger *SWLog) FromContext(ctx context.Context) *Entry {
+	entry := FromContext(ctx)
+	entry.logger = logger
+	return entry
+}
+
+// contextFields returns the fields of the context values with the given fields
+// overriding them
+func (logger *SWLog) contextFields(ctx context.Context, fields logrus.Fields) logrus.Fields {
+	if len(logger.ctxExtractors) == 0 {
+		return fields
+	}
+
+	merged := logrus.Fields{}
+	for _, extractor := range logger.ctxExtractors {
+		for k, v := range extractor(ctx) {
+			merged[k] = v
+		}
+	}
+	if len(merged) == 0 {
+		return fields
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+// DebugCtx logging in debug level with the fields of ctx
+func (logger *SWLog) DebugCtx(ctx context.Context, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Log(logrus.DebugLevel, filename, funcname, args...)
+}
+
+// InfoCtx logging in info level with the fields of ctx
+func (logger *SWLog) InfoCtx(ctx context.Context, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Log(logrus.InfoLevel, filename, funcname, args...)
+}
+
+// WarnCtx logging in warning level with the fields of ctx
+func (logger *SWLog) WarnCtx(ctx context.Context, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Log(logrus.WarnLevel, filename, funcname, args...)
+}
+
+// ErrorCtx logging in error level with the fields of ctx
+func (logger *SWLog) ErrorCtx(ctx context.Context, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Log(logrus.ErrorLevel, filename, funcname, args...)
+}
+
+// DebugfCtx logging in debug level with the given formated args and the fields
+// of ctx
+func (logger *SWLog) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Logf(logrus.DebugLevel, format, filename, funcname, args...)
+}
+
+// InfofCtx logging in info level with the fields of ctx
+func (logger *SWLog) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Logf(logrus.InfoLevel, format, filename, funcname, args...)
+}
+
+// WarnfCtx logging in warning level with the fields of ctx
+func (logger *SWLog) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Logf(logrus.WarnLevel, format, filename, funcname, args...)
+}
+
+// ErrorfCtx logging in error level with the fields of ctx
+func (logger *SWLog) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	filename, funcname := caller(logger.skip)
+	logger.FromContext(ctx).Logf(logrus.ErrorLevel, format, filename, funcname, args...)
+}
+
+// DebugCtx logging in debug level with the request-scoped logger of ctx
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.DebugLevel, filename, funcname, args...)
+}
+
+// InfoCtx logging in info level with the request-scoped logger of ctx
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.InfoLevel, filename, funcname, args...)
+}
+
+// WarnCtx logging in warning level with the request-scoped logger of ctx
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.WarnLevel, filename, funcname, args...)
+}
+
+// ErrorCtx logging in error level with the request-scoped logger of ctx
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.ErrorLevel, filename, funcname, args...)
+}
+
+// DebugfCtx logging in debug level with the given formated args and the
+// request-scoped logger of ctx
+func DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.DebugLevel, format, filename, funcname, args...)
+}
+
+// InfofCtx logging in info level with the request-scoped logger of ctx
+func InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.InfoLevel, format, filename, funcname, args...)
+}
+
+// WarnfCtx logging in warning level with the request-scoped logger of ctx
+func WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.WarnLevel, format, filename, funcname, args...)
+}
+
+// ErrorfCtx logging in error level with the request-scoped logger of ctx
+func ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	entry := FromContext(ctx)
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.ErrorLevel, format, filename, funcname, args...)
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type requestIDKey struct{}
+
+func newContextTestLogger(t *testing.T) (*SWLog, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := New(
+		WithLevel(logrus.DebugLevel),
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &LogfmtFormatter{}),
+		WithContextKey(requestIDKey{}, "request_id"),
+		WithContextExtractor(func(ctx context.Context) logrus.Fields {
+			if ctx.Value(requestIDKey{}) == nil {
+				return nil
+			}
+			return logrus.Fields{"tenant": "acme", "user": "extracted"}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, &buf
+}
+
+func TestContext(t *testing.T) {
+	logger, buf := newContextTestLogger(t)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = logger.NewContext(ctx, logrus.Fields{"user": "alice"})
+	ctx = NewContext(ctx, logrus.Fields{"attempt": 2})
+
+	logger.InfofCtx(ctx, "hello %s", "world")
+	FromContext(ctx).WithField("extra", true).Warn("from context")
+	ErrorCtx(ctx, "package level")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), buf.String())
+	}
+	for i, level := range []string{"info", "warning", "error"} {
+		if !strings.Contains(lines[i], " level="+level+" ") {
+			t.Errorf("got line %q, want level %s", lines[i], level)
+		}
+	}
+	for i, want := range []string{
+		`/context_test.go:42 func=TestContext msg="hello world" attempt=2 request_id=req-1 tenant=acme user=alice`,
+		`/context_test.go:43 func=TestContext msg="from context" attempt=2 extra=true request_id=req-1 tenant=acme user=alice`,
+		`/context_test.go:44 func=TestContext msg="package level" attempt=2 request_id=req-1 tenant=acme user=alice`,
+	} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("got line %q, want suffix %q", lines[i], want)
+		}
+	}
+}
+
+func TestContextWithoutFields(t *testing.T) {
+	logger, buf := newContextTestLogger(t)
+
+	logger.DebugCtx(context.Background(), "no fields")
+	if !strings.HasSuffix(buf.String(), "func=TestContextWithoutFields msg=\"no fields\"\n") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if entry := FromContext(context.Background()); entry.logger != SWLogger || len(entry.Data) != 0 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+}
+
+func TestContextSlogHandler(t *testing.T) {
+	logger, buf := newContextTestLogger(t)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-2")
+	ctx = logger.NewContext(ctx, logrus.Fields{"user": "bob"})
+	slog.New(NewSlogHandler(logger)).InfoContext(ctx, "slog", "user", "carol")
+
+	if !strings.HasSuffix(buf.String(), " msg=slog request_id=req-2 tenant=acme user=carol\n") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a set of fields waiting to be logged by SWLog, the fields can be
+// used as `%key%` in the log format of Formatter.
+type Entry struct {
+	logger *SWLog
+	// Data contains all the fields set by the user
+	Data logrus.Fields
+	// ctx is passed to the context extractors and the formatters, it is nil
+	// unless the entry comes from FromContext or WithContext
+	ctx context.Context
+}
+
+// WithField creates an entry with a single field
+func (logger *SWLog) WithField(key string, value interface{}) *Entry {
+	return logger.WithFields(logrus.Fields{key: value})
+}
+
+// WithFields creates an entry with multiple fields
+func (logger *SWLog) WithFields(fields logrus.Fields) *Entry {
+	entry := &Entry{logger: logger}
+	return entry.WithFields(fields)
+}
+
+// WithError creates an entry with the error as the `error` field
+func (logger *SWLog) WithError(err error) *Entry {
+	return logger.WithField(logrus.ErrorKey, err)
+}
+
+// WithField adds a single field to a copy of the entry
+func (entry *Entry) WithField(key string, value interface{}) *Entry {
+	return entry.WithFields(logrus.Fields{key: value})
+}
+
+// WithFields adds multiple fields to a copy of the entry
+func (entry *Entry) WithFields(fields logrus.Fields) *Entry {
+	data := make(logrus.Fields, len(entry.Data)+len(fields))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	return &Entry{logger: entry.logger, Data: data, ctx: entry.ctx}
+}
+
+// WithError adds the error as the `error` field to a copy of the entry
+func (entry *Entry) WithError(err error) *Entry {
+	return entry.WithField(logrus.ErrorKey, err)
+}
+
+// WithContext sets the context of a copy of the entry, the fields of the
+// registered context values are added when logging
+func (entry *Entry) WithContext(ctx context.Context) *Entry {
+	return &Entry{logger: entry.logger, Data: entry.Data, ctx: ctx}
+}
+
+// Log logging the message of input args with the entry fields
+func (entry *Entry) Log(level logrus.Level, filename string, funcname string, args ...interface{}) {
+	ctx := entry.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entry.logger.log(ctx, level, entry.Data, filename, funcname, args...)
+}
+
+// Logf logging the message with the given formated args and the entry fields
+func (entry *Entry) Logf(level logrus.Level, format string, filename string, funcname string, args ...interface{}) {
+	entry.Log(level, filename, funcname, fmt.Sprintf(format, args...))
+}
+
+// Debug logging in debug level
+func (entry *Entry) Debug(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.DebugLevel, filename, funcname, args...)
+}
+
+// Info logging in info level
+func (entry *Entry) Info(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.InfoLevel, filename, funcname, args...)
+}
+
+// Warn logging in warning level
+func (entry *Entry) Warn(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.WarnLevel, filename, funcname, args...)
+}
+
+// Error logging in error level
+func (entry *Entry) Error(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.ErrorLevel, filename, funcname, args...)
+}
+
+// Fatal logging in fatal level and calling the os.Exit()
+func (entry *Entry) Fatal(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.FatalLevel, filename, funcname, args...)
+}
+
+// Panic logging in panic level and calling the os.Panic()
+func (entry *Entry) Panic(args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Log(logrus.PanicLevel, filename, funcname, args...)
+}
+
+// Debugf logging in debug level with the given formated args
+func (entry *Entry) Debugf(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.DebugLevel, format, filename, funcname, args...)
+}
+
+// Infof logging in info level
+func (entry *Entry) Infof(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.InfoLevel, format, filename, funcname, args...)
+}
+
+// Warnf logging in warning level
+func (entry *Entry) Warnf(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.WarnLevel, format, filename, funcname, args...)
+}
+
+// Errorf logging in error level
+func (entry *Entry) Errorf(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.ErrorLevel, format, filename, funcname, args...)
+}
+
+// Fatalf logging in fatal level and calling the os.Exit()
+func (entry *Entry) Fatalf(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.FatalLevel, format, filename, funcname, args...)
+}
+
+// Panicf logging in panic level and calling the os.Panic()
+func (entry *Entry) Panicf(format string, args ...interface{}) {
+	filename, funcname := caller(entry.logger.skip)
+	entry.Logf(logrus.PanicLevel, format, filename, funcname, args...)
+}
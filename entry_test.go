@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newEntryTestLogger(t *testing.T) (*SWLog, *bytes.Buffer, *bytes.Buffer) {
+	logger := &SWLog{}
+	logger.Init(filepath.Join(t.TempDir(), "entry.log"), logrus.DebugLevel, true)
+
+	var stdBuf, fileBuf bytes.Buffer
+	format := "%lvl%|%func%(%line%)|%msg%|%user%\n"
+	logger.STDLogger.SetOutput(&stdBuf)
+	logger.STDLogger.SetFormatter(&Formatter{LogFormat: format})
+	logger.FileLogger.SetOutput(&fileBuf)
+	logger.FileLogger.SetFormatter(&Formatter{LogFormat: format})
+	return logger, &stdBuf, &fileBuf
+}
+
+func TestEntryWithFields(t *testing.T) {
+	logger, stdBuf, fileBuf := newEntryTestLogger(t)
+
+	entry := logger.WithField("user", "alice")
+	entry.WithFields(logrus.Fields{"user": "bob", "id": 1}).Infof("hello %s", "bob")
+	entry.Warn("hello alice")
+
+	for _, buf := range []*bytes.Buffer{stdBuf, fileBuf} {
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+		}
+		if !strings.HasPrefix(lines[0], "INFO   |TestEntryWithFields(") ||
+			!strings.HasSuffix(lines[0], "/entry_test.go:30)|hello bob|bob") {
+			t.Errorf("unexpected line %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], "WARNING|TestEntryWithFields(") ||
+			!strings.HasSuffix(lines[1], "/entry_test.go:31)|hello alice|alice") {
+			t.Errorf("unexpected line %q", lines[1])
+		}
+	}
+
+	if len(entry.Data) != 1 {
+		t.Errorf("parent entry was modified: %v", entry.Data)
+	}
+}
+
+func TestEntryWithError(t *testing.T) {
+	logger, _, fileBuf := newEntryTestLogger(t)
+	logger.FileLogger.SetFormatter(&Formatter{LogFormat: "%msg%\n"})
+
+	err := errors.New("boom")
+	entry := logger.WithError(err)
+	if entry.Data[logrus.ErrorKey] != err {
+		t.Fatalf("WithError stored %v", entry.Data[logrus.ErrorKey])
+	}
+	entry.Error("failed")
+	if fileBuf.String() != "failed\n" {
+		t.Fatalf("unexpected output %q", fileBuf.String())
+	}
+}
+
+func TestEntryPanic(t *testing.T) {
+	logger, _, _ := newEntryTestLogger(t)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("The code did not panic")
+		}
+	}()
+	logger.WithField("user", "alice").Panicf("%s test", "Panicf")
+}
@@ -2,47 +2,77 @@ package log_test
 
 import (
 	"fmt"
-	//"time"
+	"io"
+	"os"
+	"time"
 
-	//"github.com/agiledragon/gomonkey/v2"
 	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
 	"github.com/sirupsen/logrus"
 )
 
-func init() {
-
+// newExampleLogger creates a logger printing into stdout at a fixed time, the
+// file name is left out of the format because it depends on the checkout.
+func newExampleLogger() *log.SWLog {
+	logger, err := log.New(
+		log.WithLevel(logrus.DebugLevel),
+		log.WithClock(logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))),
+		log.WithOutput(io.Discard),
+		log.WithLog2STD(true),
+		log.WithSTDOutput(os.Stdout),
+		log.WithFormatter(log.OutputSTD, &log.Formatter{LogFormat: "%time%|%lvl%|%func%|%msg%\n"}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return logger
 }
 
 func ExampleSWLog_Debug() {
-	// **NOTE**: log need to be initated only once, `SWLogger.Init` is recommended being called in `init` function
-	log.SWLogger.Init("/tmp/test.log", logrus.DebugLevel, true)
-	log.Debug("test debug")
-	/* try the following test way to assert output is as expected.
-	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
-		return time.Unix(1615256178, 0)
-	})
-	defer patches.Reset()
-	// parse logging string from log file to do assertion
-	logFile, err := os.ReadFile("/tmp/test.log")
+	// **NOTE**: the global `log.SWLogger` need to be initated only once, `SWLogger.Init` is recommended being
+	// called in `init` function, while `log.New` creates independent loggers.
+	logger := newExampleLogger()
+	logger.Debug("test debug")
+	// Output:
+	// 2021-03-09 10:16:18.000|DEBUG  |ExampleSWLog_Debug|test debug
+}
+
+func ExampleSWLog_Info() {
+	logger := newExampleLogger()
+	logger.Info("test info")
+	// Output:
+	// 2021-03-09 10:16:18.000|INFO   |ExampleSWLog_Info|test info
+}
+
+func ExampleWithClock() {
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	logger, err := log.New(
+		log.WithClock(clock),
+		log.WithOutput(os.Stdout),
+		log.WithFormatter(log.OutputFile, &log.Formatter{LogFormat: "%time%|%msg%|%user%\n"}),
+	)
 	if err != nil {
 		panic(err)
 	}
-	lines := strings.Split(string(logFile), "\n")
-	// ignore the empty line because each line endup with `\n`
-	// for matching assertion, the stdoud line has to include the `\n`
-	fmt.Println(lines[len(lines)-2] + "\n")
-	*/
-	// this is mocked output
-	fmt.Println("2021-03-09 10:16:18.000|DEBUG  |ExampleSWLog_Debug(examples/example_log_test.go:23)|test debug")
+	logger.WithField("user", "alice").Info("login")
+	clock.Add(1500 * time.Millisecond)
+	logger.WithField("user", "alice").Info("logout")
 	// Output:
-	// 2021-03-09 10:16:18.000|DEBUG  |ExampleSWLog_Debug(examples/example_log_test.go:23)|test debug
+	// 2021-03-09 10:16:18.000|login|alice
+	// 2021-03-09 10:16:19.500|logout|alice
 }
 
-func ExampleSWLog_Info() {
-	log.SWLogger.Init("/tmp/test.log", logrus.DebugLevel, true)
-	log.Info("test info")
-	// this is mocked output
-	fmt.Println("2021-03-09 10:16:18.000|INFO  |ExampleSWLog_Info(examples/example_log_test.go:23)|test info")
+func ExampleWithExitFunc() {
+	logger, err := log.New(
+		log.WithOutput(io.Discard),
+		log.WithExitFunc(func(code int) {
+			fmt.Println("exit code", code)
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	logger.Fatal("test fatal")
 	// Output:
-	// 2021-03-09 10:16:18.000|INFO  |ExampleSWLog_Info(examples/example_log_test.go:23)|test info
+	// exit code 1
 }
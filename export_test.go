@@ -0,0 +1,8 @@
+package log
+
+// the internals used by the tests of package log_test, which import logtest
+var (
+	ReadLogFile    = readLogFile
+	RotateNameLess = rotateNameLess
+	SyslogSockets  = &syslogSockets
+)
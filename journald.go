@@ -0,0 +1,200 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultJournaldSocket is the native protocol socket of systemd-journald
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldReservedFields are the journal fields set by the sink, the entry
+// fields of the same names are prefixed by `FIELDS_`
+var journaldReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"SYSLOG_IDENTIFIER": true,
+}
+
+// JournaldConfig configures the journald sink
+type JournaldConfig struct {
+	// Socket is the path of the journald socket, DefaultJournaldSocket by
+	// default
+	Socket string
+	// Identifier is the SYSLOG_IDENTIFIER of the entries, the program name by
+	// default
+	Identifier string
+	// Severities overrides the PRIORITY of some log levels, see
+	// DefaultSyslogSeverities
+	Severities map[logrus.Level]SyslogSeverity
+}
+
+// JournaldSink is a Sink sending the entries to systemd-journald over its
+// native protocol, the calling info and the entry fields become journal
+// fields, i.e. the `user` field is `USER`. It reconnects once the socket
+// fails.
+type JournaldSink struct {
+	config     JournaldConfig
+	severities map[logrus.Level]SyslogSeverity
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournaldSink connects to the journald socket, the sink may supplement the
+// log file with `log.New(log.WithSink(sink))` or replace it with
+// `log.New(log.WithSink(sink), log.WithoutLogFile())`.
+func NewJournaldSink(config JournaldConfig) (*JournaldSink, error) {
+	if config.Socket == "" {
+		config.Socket = DefaultJournaldSocket
+	}
+	if config.Identifier == "" {
+		config.Identifier = filepath.Base(os.Args[0])
+	}
+	severities, err := syslogSeverities(config.Severities)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &JournaldSink{config: config, severities: severities}
+	if err := sink.connect(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// connect dials the journald socket
+func (sink *JournaldSink) connect() error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: sink.config.Socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("connect journald %s error: %s", sink.config.Socket, err.Error())
+	}
+	sink.conn = conn
+	return nil
+}
+
+// WriteEntry sends the entry, the entry is sent once more over a new
+// connection if it fails
+func (sink *JournaldSink) WriteEntry(entry *logrus.Entry) error {
+	msg := sink.message(entry)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.conn != nil {
+		if err := writeJournald(sink.conn, msg); err == nil {
+			return nil
+		}
+		sink.conn.Close()
+		sink.conn = nil
+	}
+	if err := sink.connect(); err != nil {
+		return err
+	}
+	if err := writeJournald(sink.conn, msg); err != nil {
+		sink.conn.Close()
+		sink.conn = nil
+		return fmt.Errorf("write journald error: %s", err.Error())
+	}
+	return nil
+}
+
+// Close closes the connection
+func (sink *JournaldSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.conn == nil {
+		return nil
+	}
+	err := sink.conn.Close()
+	sink.conn = nil
+	return err
+}
+
+// message builds the native protocol datagram of the entry
+func (sink *JournaldSink) message(entry *logrus.Entry) []byte {
+	severity, ok := sink.severities[entry.Level]
+	if !ok {
+		severity = SyslogDebug
+	}
+
+	buf := &bytes.Buffer{}
+	writeJournaldField(buf, "MESSAGE", entry.Message)
+	writeJournaldField(buf, "PRIORITY", fmt.Sprint(int(severity)))
+	writeJournaldField(buf, "SYSLOG_IDENTIFIER", sink.config.Identifier)
+
+	filename, funcname := EntryCaller(entry)
+	if i := strings.LastIndexByte(filename, ':'); i >= 0 {
+		writeJournaldField(buf, "CODE_FILE", filename[:i])
+		writeJournaldField(buf, "CODE_LINE", filename[i+1:])
+	}
+	if funcname != "" {
+		writeJournaldField(buf, "CODE_FUNC", funcname)
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journaldFieldName(k)
+		if journaldReservedFields[name] {
+			name = "FIELDS_" + name
+		}
+		writeJournaldField(buf, name, logfmtValue(entry.Data[k]))
+	}
+	return buf.Bytes()
+}
+
+// writeJournaldField writes `NAME=value\n` into buf, the values with newlines
+// are written in the binary form: the name, a newline, the little endian
+// 64 bits size of the value, the value and a newline
+func writeJournaldField(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldFieldName converts the field key into a journal field name, which
+// has uppercase letters, digits and underscores only, starts with a letter
+// and is up to 64 characters long
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestJournaldSinkLargeEntry(t *testing.T) {
+	conn, socket := listenJournald(t)
+	sink, err := NewJournaldSink(JournaldConfig{Socket: socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := New(WithSink(sink), WithoutLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	message := strings.Repeat("x", 4<<20)
+	logger.Info(message)
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, oobn, _, _, err := conn.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("no control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("no file descriptor: %v", err)
+	}
+	file := os.NewFile(uintptr(fds[0]), "journal-entry")
+	defer file.Close()
+	data := make([]byte, 5<<20)
+	n, _ := file.ReadAt(data, 0)
+	if fields := parseJournald(t, data[:n]); fields["MESSAGE"] != message {
+		t.Fatalf("got message of %d bytes, want %d bytes", len(fields["MESSAGE"]), len(message))
+	}
+	if _, err := file.Write([]byte("x")); err == nil {
+		t.Fatal("the memfd is not sealed")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package log
+
+import "net"
+
+// writeJournald sends the datagram, journald runs on linux only
+func writeJournald(conn *net.UnixConn, msg []byte) error {
+	_, err := conn.Write(msg)
+	return err
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenJournald listens on a local journald socket
+func listenJournald(t *testing.T) (*net.UnixConn, string) {
+	socket := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return conn, socket
+}
+
+// parseJournald parses the fields of a native protocol datagram
+func parseJournald(t *testing.T, msg []byte) map[string]string {
+	fields := map[string]string{}
+	for len(msg) > 0 {
+		i := bytes.IndexAny(msg, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid datagram %q", msg)
+		}
+		name := string(msg[:i])
+		if msg[i] == '=' {
+			end := bytes.IndexByte(msg, '\n')
+			fields[name] = string(msg[i+1 : end])
+			msg = msg[end+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(msg[i+1 : i+9])
+		fields[name] = string(msg[i+9 : i+9+int(size)])
+		msg = msg[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournaldSink(t *testing.T) {
+	conn, socket := listenJournald(t)
+	sink, err := NewJournaldSink(JournaldConfig{Socket: socket, Identifier: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := New(WithSink(sink), WithoutLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.WithFields(map[string]interface{}{"user-id": 42, "message": "clash", "_private": "x", "multi": "a\nb"}).Warn("hello")
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournald(t, buf[:n])
+	for name, want := range map[string]string{
+		"MESSAGE":           "hello",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "app",
+		"CODE_LINE":         "61",
+		"CODE_FUNC":         "TestJournaldSink",
+		"USER_ID":           "42",
+		"FIELDS_MESSAGE":    "clash",
+		"PRIVATE":           "x",
+		"MULTI":             "a\nb",
+	} {
+		if fields[name] != want {
+			t.Errorf("got %s=%q, want %q", name, fields[name], want)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "/journald_test.go") {
+		t.Errorf("unexpected CODE_FILE %q", fields["CODE_FILE"])
+	}
+}
+
+func TestJournaldSinkReconnect(t *testing.T) {
+	conn, socket := listenJournald(t)
+	sink, err := NewJournaldSink(JournaldConfig{Socket: socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := New(WithSink(sink), WithoutLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// journald restarts on a new socket file
+	conn.Close()
+	os.Remove(socket)
+	conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	logger.Info("after restart")
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := parseJournald(t, buf[:n]); fields["MESSAGE"] != "after restart" {
+		t.Fatalf("unexpected fields %q", fields)
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	for key, want := range map[string]string{
+		"user":                  "USER",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"__reserved":            "RESERVED",
+		"1st":                   "F_1ST",
+		"":                      "F_",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	} {
+		if got := journaldFieldName(key); got != want {
+			t.Errorf("journaldFieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
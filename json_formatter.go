@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// JSONKeyTime is the JSON key of the log time
+	JSONKeyTime = "time"
+	// JSONKeyLevel is the JSON key of the log level
+	JSONKeyLevel = "level"
+	// JSONKeyCaller is the JSON key of the file name and line number
+	JSONKeyCaller = "caller"
+	// JSONKeyFunc is the JSON key of the function name
+	JSONKeyFunc = "func"
+	// JSONKeyMsg is the JSON key of the log message
+	JSONKeyMsg = "msg"
+)
+
+// JSONFormatter implements logrus.Formatter interface, it outputs one JSON
+// object per line with the keys time, level, caller, func and msg first,
+// followed by the entry fields sorted by key. Fields clashing with those keys
+// are prefixed with `fields.`.
+type JSONFormatter struct {
+	// Timestamp format, time.RFC3339Nano by default
+	TimestampFormat string
+}
+
+// Format building log message.
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339Nano
+	}
+	filename, funcname := EntryCaller(entry)
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	writeJSONPair(buf, JSONKeyTime, entry.Time.Format(timestampFormat), true)
+	writeJSONPair(buf, JSONKeyLevel, entry.Level.String(), false)
+	writeJSONPair(buf, JSONKeyCaller, filename, false)
+	writeJSONPair(buf, JSONKeyFunc, funcname, false)
+	writeJSONPair(buf, JSONKeyMsg, entry.Message, false)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok {
+			// errors are mostly structs without exported fields
+			v = err.Error()
+		}
+		key := k
+		switch k {
+		case JSONKeyTime, JSONKeyLevel, JSONKeyCaller, JSONKeyFunc, JSONKeyMsg:
+			key = "fields." + k
+		}
+		writeJSONPair(buf, key, v, false)
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// writeJSONPair writes `"key":value` into buf
+func writeJSONPair(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	// marshaling a string never fails
+	k, _ := json.Marshal(key)
+	v, err := json.Marshal(value)
+	if err != nil {
+		// fall back to the printed value rather than losing the whole entry
+		v, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(v)
+}
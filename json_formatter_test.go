@@ -0,0 +1,58 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"user":  "alice",
+		"id":    7,
+		"error": errors.New("boom"),
+		"msg":   "clash",
+	}).WithContext(withCaller(context.Background(), "log/json_formatter_test.go:1", "TestJSONFormatter"))
+	entry.Time = time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC)
+	entry.Level = logrus.InfoLevel
+	entry.Message = "hello \"json\""
+
+	b, err := (&JSONFormatter{}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"time":"2021-03-09T10:16:18Z","level":"info","caller":"log/json_formatter_test.go:1",` +
+		`"func":"TestJSONFormatter","msg":"hello \"json\"","error":"boom","id":7,"fields.msg":"clash","user":"alice"}` + "\n"
+	if string(b) != want {
+		t.Fatalf("got  %s\nwant %s", b, want)
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	logger := &SWLog{}
+	logger.Init(filepath.Join(t.TempDir(), "json.log"), logrus.DebugLevel, true)
+	var stdBuf, fileBuf bytes.Buffer
+	logger.STDLogger.SetOutput(&stdBuf)
+	logger.FileLogger.SetOutput(&fileBuf)
+
+	logger.SetFormatter(OutputFile, &JSONFormatter{})
+	logger.WithField("user", "alice").Info("hello")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(fileBuf.Bytes(), &record); err != nil {
+		t.Fatalf("file output is not JSON: %s", err)
+	}
+	if record["msg"] != "hello" || record["user"] != "alice" || record["func"] != "TestSetFormatter" {
+		t.Fatalf("unexpected record %v", record)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(stdBuf.String()), "|hello") {
+		t.Fatalf("stderr output should keep the default format: %q", stdBuf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelHandler is an http.Handler reading and setting the log level at
+// runtime, i.e. on an admin port. GET returns the level name of LevelFromStr,
+// PUT and POST set the level named by the `level` query parameter or by the
+// body, i.e. `curl -X PUT -d DEBUG host/loglevel`. The level set reverts to the
+// previous one after the TTL, which the `ttl` query parameter overrides, i.e.
+// `?ttl=10m`, and `ttl=0` keeps the level.
+type LevelHandler struct {
+	logger *SWLog
+	ttl    time.Duration
+
+	mu sync.Mutex
+	// revert reverts the temporary level, nil if the level is not temporary
+	revert *time.Timer
+	// previous is the level before the temporary levels
+	previous logrus.Level
+}
+
+// NewLevelHandler creates a LevelHandler of logger, the levels set revert to
+// the previous one after ttl, they are kept if ttl is 0.
+func NewLevelHandler(logger *SWLog, ttl time.Duration) *LevelHandler {
+	return &LevelHandler{logger: logger, ttl: ttl}
+}
+
+// ServeHTTP returns or sets the log level, it fails with 503 until the logger
+// is set up, i.e. SWLogger before Init
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.logger.isSetup {
+		http.Error(w, "logger is not set up", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.writeLevel(w)
+	case http.MethodPut, http.MethodPost:
+		name := r.URL.Query().Get("level")
+		if name == "" {
+			body, err := io.ReadAll(io.LimitReader(r.Body, 1024))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("read body error: %s", err.Error()), http.StatusBadRequest)
+				return
+			}
+			name = string(body)
+		}
+		level, ok := LevelFromStr[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown log level %q", name), http.StatusBadRequest)
+			return
+		}
+
+		ttl := h.ttl
+		if s := r.URL.Query().Get("ttl"); s != "" {
+			var err error
+			if ttl, err = time.ParseDuration(s); err != nil || ttl < 0 {
+				http.Error(w, fmt.Sprintf("invalid ttl %q", s), http.StatusBadRequest)
+				return
+			}
+		}
+		h.setLevel(level, ttl)
+		h.writeLevel(w)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// setLevel sets the level, which reverts to the level before the temporary
+// levels after ttl
+func (h *LevelHandler) setLevel(level logrus.Level, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	previous := h.logger.GetLevel()
+	if h.revert != nil {
+		// keep reverting to the level before the temporary levels
+		h.revert.Stop()
+		h.revert = nil
+		previous = h.previous
+	}
+	h.logger.SetLevel(level)
+	if ttl == 0 {
+		h.logger.Infof("log level is set to %s", levelName(level))
+		return
+	}
+
+	h.previous = previous
+	var revert *time.Timer
+	revert = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// the level has been set again
+		if h.revert != revert {
+			return
+		}
+		h.revert = nil
+		h.logger.SetLevel(previous)
+		h.logger.Infof("log level reverts to %s", levelName(previous))
+	})
+	h.revert = revert
+	h.logger.Infof("log level is set to %s for %s", levelName(level), ttl)
+}
+
+// writeLevel writes the name of the current level
+func (h *LevelHandler) writeLevel(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, levelName(h.logger.GetLevel()))
+}
+
+// levelName returns the name of the level in LevelFromStr
+func levelName(level logrus.Level) string {
+	for name, l := range LevelFromStr {
+		if l == level {
+			return name
+		}
+	}
+	return strings.ToUpper(level.String())
+}
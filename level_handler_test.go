@@ -0,0 +1,131 @@
+package log
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func serveLevel(t *testing.T, h http.Handler, method string, target string, body string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w.Code, strings.TrimSpace(w.Body.String())
+}
+
+func TestLevelHandler(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+	h := NewLevelHandler(logger, 0)
+
+	if code, body := serveLevel(t, h, http.MethodGet, "/", ""); code != http.StatusOK || body != "INFO" {
+		t.Fatalf("GET returned %d %q", code, body)
+	}
+	if code, body := serveLevel(t, h, http.MethodPut, "/", " debug\n"); code != http.StatusOK || body != "DEBUG" {
+		t.Fatalf("PUT returned %d %q", code, body)
+	}
+	if logger.GetLevel() != logrus.DebugLevel || !strings.Contains(buf.String(), `msg="log level is set to DEBUG"`) {
+		t.Fatalf("got level %s, output %q", logger.GetLevel(), buf.String())
+	}
+	if code, body := serveLevel(t, h, http.MethodPost, "/?level=WARN", ""); code != http.StatusOK || body != "WARN" {
+		t.Fatalf("POST returned %d %q", code, body)
+	}
+
+	for _, c := range []struct {
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{http.MethodPut, "/", "VERBOSE", http.StatusBadRequest},
+		{http.MethodPut, "/?ttl=-1s", "DEBUG", http.StatusBadRequest},
+		{http.MethodPut, "/?ttl=soon", "DEBUG", http.StatusBadRequest},
+		{http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+	} {
+		if code, body := serveLevel(t, h, c.method, c.target, c.body); code != c.code {
+			t.Errorf("%s %s %q returned %d %q, want %d", c.method, c.target, c.body, code, body, c.code)
+		}
+	}
+	if logger.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("the invalid requests changed the level to %s", logger.GetLevel())
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	logger, _ := newBufferTestLogger(t)
+	h := NewLevelHandler(logger, time.Hour)
+
+	serveLevel(t, h, http.MethodPut, "/", "DEBUG")
+	// the levels set during the TTL revert to the level before them
+	serveLevel(t, h, http.MethodPut, "/?ttl=20ms", "ERROR")
+	if logger.GetLevel() != logrus.ErrorLevel {
+		t.Fatalf("got level %s, want error", logger.GetLevel())
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for logger.GetLevel() != logrus.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("got level %s, want the level reverted to info", logger.GetLevel())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// ttl=0 keeps the level and cancels the revert
+	serveLevel(t, h, http.MethodPut, "/?ttl=20ms", "DEBUG")
+	serveLevel(t, h, http.MethodPut, "/?ttl=0", "WARN")
+	time.Sleep(50 * time.Millisecond)
+	if logger.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("got level %s, want warning", logger.GetLevel())
+	}
+}
+
+func TestLevelHandlerNotSetup(t *testing.T) {
+	h := NewLevelHandler(&SWLog{}, 0)
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		if code, body := serveLevel(t, h, method, "/?level=DEBUG", ""); code != http.StatusServiceUnavailable {
+			t.Errorf("%s returned %d %q", method, code, body)
+		}
+	}
+}
+
+// the levels are set while other goroutines are logging, run with -race
+func TestLevelHandlerWhileLogging(t *testing.T) {
+	logger, err := New(WithOutput(io.Discard), WithLog2STD(true), WithSTDOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewLevelHandler(logger, 0)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Debug("debug")
+					logger.WithField("user", "alice").Info("info")
+				}
+			}
+		}()
+	}
+	for _, level := range []string{"DEBUG", "WARN", "INFO", "ERROR", "DEBUG"} {
+		if code, body := serveLevel(t, h, http.MethodPut, "/?level="+level, ""); code != http.StatusOK || body != level {
+			t.Errorf("PUT %s returned %d %q", level, code, body)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if logger.GetLevel() != logrus.DebugLevel || logger.STDLogger.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("got levels %s and %s", logger.GetLevel(), logger.STDLogger.GetLevel())
+	}
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+)
+
+// syncer is implemented by the outputs which can commit the written bytes to
+// the storage, like *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// Sync writes the entries queued in async mode and commits the written bytes
+// of every output to the storage.
+func (logger *SWLog) Sync() error {
+	logger.Flush()
+
+	var errs []error
+	if logger.FileLogger != nil {
+		errs = append(errs, syncOutput(logger.FileLogger.Out))
+	}
+	if logger.STDLogger != nil && logger.IsLog2STD {
+		errs = append(errs, syncOutput(logger.STDLogger.Out))
+	}
+	for _, sink := range logger.sinks {
+		if sink, ok := sink.(syncer); ok {
+			errs = append(errs, sink.Sync())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncOutput commits the written bytes of w to the storage if possible
+func syncOutput(w io.Writer) error {
+	switch w := w.(type) {
+	case *rotatelogs.RotateLogs:
+		// rotatelogs does not expose its file, but syncing any descriptor of
+		// the file commits all its written bytes
+		name := w.CurrentFileName()
+		if name == "" {
+			return nil
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return f.Sync()
+	case *os.File:
+		// terminals and pipes have nothing to commit, and fail to sync
+		if fi, err := w.Stat(); err != nil || !fi.Mode().IsRegular() {
+			return nil
+		}
+		return w.Sync()
+	case syncer:
+		return w.Sync()
+	default:
+		return nil
+	}
+}
+
+// Close writes the entries queued in async mode, stops the writing goroutine
+// and closes the log file opened by the logger, which must not be used to log
+// into the file any more.
+func (logger *SWLog) Close() error {
+	if logger.async != nil {
+		logger.async.close()
+	}
+
+	err := logger.Sync()
+	for _, closer := range logger.closers {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// Shutdown closes the logger like Close, but it gives up waiting once the
+// context is done, i.e. during the graceful shutdown of a server.
+func (logger *SWLog) Shutdown(ctx context.Context) error {
+	closed := make(chan error, 1)
+	go func() {
+		closed <- logger.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exit closes the logger before exiting the process, it is the exit function
+// of the logrus loggers. The exit function set by WithExitFunc is called
+// instead once the logger is synced.
+func (logger *SWLog) exit(code int) {
+	if logger.exitFunc != nil {
+		logger.Sync()
+		logger.exitFunc(code)
+		return
+	}
+	logger.Close()
+	os.Exit(code)
+}
+
+// Sync commits the logged entries of SWLogger to the storage
+func Sync() error {
+	return SWLogger.Sync()
+}
+
+// Close closes SWLogger, see SWLog.Close
+func Close() error {
+	return SWLogger.Close()
+}
+
+// Shutdown closes SWLogger unless the context is done first, see SWLog.Shutdown
+func Shutdown(ctx context.Context) error {
+	return SWLogger.Shutdown(ctx)
+}
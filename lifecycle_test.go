@@ -0,0 +1,118 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func readLogFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestClose(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "close.log")
+	logger, err := New(
+		WithLogFile(logFile),
+		WithFormatter(OutputFile, &Formatter{LogFormat: "%msg%\n"}),
+		WithAsync(1000, OverflowBlock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		logger.Info("x")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(readLogFile(t, logFile), "x\n"); got != 100 {
+		t.Fatalf("got %d entries, want 100", got)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("second Close failed: %s", err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	logger, err := New(WithLogFile(filepath.Join(t.TempDir(), "sync.log")), WithLog2STD(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("synced")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	SWLogger.Init(testLogFile, logrus.DebugLevel, true)
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	w := newGateWriter()
+	logger := newAsyncTestLogger(t, w, 10, OverflowBlock)
+	logger.Info("blocked")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := logger.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want deadline exceeded", err)
+	}
+
+	close(w.open)
+	if err := logger.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.String(); got != "blocked\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestFatalClose(t *testing.T) {
+	logFile := os.Getenv("FATAL_CLOSE_LOG_FILE")
+	if logFile != "" {
+		logger, err := New(
+			WithLogFile(logFile),
+			WithFormatter(OutputFile, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+			WithAsync(1000, OverflowBlock),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			logger.Info("x")
+		}
+		logger.Fatal("Fatal test")
+		return
+	}
+
+	logFile = filepath.Join(t.TempDir(), "fatal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=TestFatalClose")
+	cmd.Env = append(os.Environ(), "FATAL_CLOSE_LOG_FILE="+logFile)
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); !ok || e.Success() {
+		t.Fatalf("process ran with %v which is not expected", err)
+	}
+
+	content := readLogFile(t, logFile)
+	if got := strings.Count(content, "INFO   |x\n"); got != 100 {
+		t.Fatalf("got %d entries, want 100", got)
+	}
+	if !strings.HasSuffix(content, "FATAL  |Fatal test\n") {
+		t.Fatalf("the fatal entry is missing in %q", content)
+	}
+}
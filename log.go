@@ -2,12 +2,13 @@
 package log
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
@@ -43,6 +44,18 @@ const (
 	WeekHours = 7 * 24
 )
 
+// Output selects the outputs of SWLog
+type Output int
+
+const (
+	// OutputSTD is the stderr output
+	OutputSTD Output = 1 << iota
+	// OutputFile is the log file output
+	OutputFile
+	// OutputBoth is both the stderr and the log file output
+	OutputBoth = OutputSTD | OutputFile
+)
+
 // SWLog wrap the log system for
 type SWLog struct {
 	// log will output log into log file and stderr
@@ -51,12 +64,41 @@ type SWLog struct {
 	IsLog2STD  bool
 	LogFile    string
 	LogLevel   logrus.Level
+	// levelMu serializes the level changes after setup
+	levelMu sync.Mutex
 	// skip is the number of stack frames to ascend
 	skip int
 	// isSetup is to make sure SWLog has been setup
 	isSetup bool
 	// raw logging without format
 	isRaw bool
+	// colors of stderr logging
+	colors     map[logrus.Level]ColorFormat
+	colorParts ColorPart
+	// outputs and formatters set by the options of New
+	fileOutput    io.Writer
+	stdOutput     io.Writer
+	fileFormatter logrus.Formatter
+	stdFormatter  logrus.Formatter
+	// log into stderr only if the log file can not be opened
+	stdFallback bool
+	// rotation policy of the log file
+	rotation RotationConfig
+	// async logging queue, nil in sync mode
+	async       *asyncQueue
+	asyncSize   int
+	asyncPolicy OverflowPolicy
+	// closers are closed along with the logger
+	closers []io.Closer
+	// exitFunc and panicFunc replace exiting and panicking after logging
+	exitFunc  func(code int)
+	panicFunc func(entry *logrus.Entry)
+	// clock is the time source of the entries and the rotation
+	clock Clock
+	// ctxExtractors add the fields of the context values
+	ctxExtractors []ContextExtractor
+	// sinks receive the entries along with stderr and the log file
+	sinks []Sink
 }
 
 // Formatter implements logrus.Formatter interface.
@@ -67,10 +109,10 @@ type Formatter struct {
 	// Also can include custom fields but limited to strings.
 	// All of fields need to be wrapped inside %% i.e %time% %msg%
 	LogFormat string
-	// file name and line number where calling the LOG/INFO/DEBUG...
-	FileName string
-	// function name where calling the LOG/INFO/DEBUG...
-	FuncName string
+	// Colors of each log level, no color is used if it is nil
+	Colors map[logrus.Level]ColorFormat
+	// ColorParts is the colored parts of the log line, ColorLevel by default
+	ColorParts ColorPart
 }
 
 // Format building log message.
@@ -94,7 +136,19 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	}
 
 	output = strings.Replace(output, "%time%", currentDate[0]+" "+currentTime[len(currentTime)-1], 1)
-	output = strings.Replace(output, "%msg%", entry.Message, 1)
+	colorFormat, colored := f.Colors[entry.Level]
+	colorParts := f.ColorParts
+	if colorParts == 0 {
+		colorParts = ColorLevel
+	}
+	colorize := func(part ColorPart, s string) string {
+		if !colored || colorParts&part == 0 {
+			return s
+		}
+		return colorFormat.Sprint(s)
+	}
+
+	output = strings.Replace(output, "%msg%", colorize(ColorMessage, entry.Message), 1)
 
 	level := strings.ToUpper(entry.Level.String())
 	// keep log level info left-justifying
@@ -103,10 +157,12 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 			level += " "
 		}
 	}
-	output = strings.Replace(output, "%lvl%", level, 1)
+	output = strings.Replace(output, "%lvl%", colorize(ColorLevel, level), 1)
 
-	output = strings.Replace(output, "%line%", f.FileName, 1)
-	output = strings.Replace(output, "%func%", f.FuncName, 1)
+	filename, funcname := EntryCaller(entry)
+	filename, funcname = colorize(ColorCaller, filename), colorize(ColorCaller, funcname)
+	output = strings.Replace(output, "%line%", filename, 1)
+	output = strings.Replace(output, "%func%", funcname, 1)
 
 	for k, v := range entry.Data {
 		if s, ok := v.(string); ok {
@@ -119,10 +175,6 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 
 type NoFormatter struct {
 	LogFormat string
-	// file name and line number where calling the LOG/INFO/DEBUG...
-	FileName string
-	// function name where calling the LOG/INFO/DEBUG...
-	FuncName string
 }
 
 func (f *NoFormatter) Format(entry *logrus.Entry) ([]byte, error) {
@@ -132,11 +184,20 @@ func (f *NoFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return []byte(output), nil
 }
 
-// Init setup SWLogger before running
+// Init setup SWLogger before running, it exits the process if SWLogger can
+// not be setup, see InitE for the error returning version.
 func (logger *SWLog) Init(logFile string, level logrus.Level, log2STD bool) {
+	if err := logger.InitE(logFile, level, log2STD); err != nil {
+		logrus.Fatalf("%s", err.Error())
+	}
+}
+
+// InitE setup SWLogger before running with the given options, it returns the
+// error instead of exiting the process if SWLogger can not be setup.
+func (logger *SWLog) InitE(logFile string, level logrus.Level, log2STD bool, opts ...Option) error {
 	if logger.isSetup {
 		logger.Debug("no need to setup, swlogger is already setup!")
-		return
+		return nil
 	}
 
 	// init log level
@@ -148,18 +209,23 @@ func (logger *SWLog) Init(logFile string, level logrus.Level, log2STD bool) {
 		// change the log file with parameter logFile
 		logger.LogFile = logFile
 	}
-	if logger.LogFile == "" {
-		logrus.Fatalf("Log file or directory is not set")
+
+	// init IsLog2STD
+	logger.IsLog2STD = log2STD
+
+	for _, opt := range opts {
+		opt(logger)
 	}
-	// monitor requires the permission of the log directory as `o+rx' and log file as `o+r'
-	dir := filepath.Dir(logger.LogFile)
-	if _, err := os.Stat(dir); err != nil {
-		if !os.IsExist(err) {
-			err := os.MkdirAll(dir, 0777)
-			if err != nil {
-				logrus.Fatalf("Fail to mkdir %s; %s", dir, err.Error())
-			}
-		}
+	return logger.setup()
+}
+
+// setup creates the file and stderr loggers of SWLog with its configuration
+func (logger *SWLog) setup() error {
+	if logger.clock == nil {
+		logger.clock = systemClock{}
+	}
+	if err := logger.rotation.Validate(); err != nil {
+		return fmt.Errorf("invalid rotation config: %w", err)
 	}
 
 	// init file logrus logger
@@ -167,45 +233,130 @@ func (logger *SWLog) Init(logFile string, level logrus.Level, log2STD bool) {
 		Level:     logger.LogLevel,
 		Hooks:     make(logrus.LevelHooks),
 		Formatter: &Formatter{},
+		ExitFunc:  logger.exit,
+	}
+	if logger.fileFormatter != nil {
+		logger.FileLogger.Formatter = logger.fileFormatter
 	}
 
-	// init logrotate
-	writer, err := rotatelogs.New(
-		logger.LogFile+".%Y-%m-%d",
-		// create a new Option that sets the symbolic link name that gets linked to the current file name being used.
-		rotatelogs.WithLinkName(logger.LogFile),
-
-		// create a new Option that sets the time between rotation(default 24 hours).
-		rotatelogs.WithRotationTime(DayHours*time.Hour),
-
-		// create a new Option that sets the number of files should be kept before it gets purged from the file system.
-		//rotatelogs.WithRotationCount(1000),
-		// creates a new Option that sets the max age of a log file before it gets purged from the file system.
-		rotatelogs.WithMaxAge(WeekHours*time.Hour),
-
-		// max rotated file size is 512Mb
-		rotatelogs.WithRotationSize(512*1024),
-	)
-	if err != nil {
-		logrus.Fatalf("config local file system for logger error: %s", err.Error())
+	var fallbackErr error
+	if logger.fileOutput != nil {
+		logger.FileLogger.Out = logger.fileOutput
+	} else {
+		writer, err := logger.newFileWriter()
+		if err != nil {
+			if !logger.stdFallback {
+				return err
+			}
+			// keep on logging into stderr only
+			fallbackErr = err
+			logger.IsLog2STD = true
+			logger.FileLogger.Out = io.Discard
+		} else {
+			logger.FileLogger.Out = writer
+		}
 	}
-	logger.FileLogger.SetOutput(writer)
 
 	// init log stack frames to ascend
-	logger.skip = 2
-
-	// init IsLog2STD
-	logger.IsLog2STD = log2STD
+	logger.skip = 1
 
 	// init logrus.logger
 	logger.STDLogger = &logrus.Logger{
-		Out:       os.Stderr,
-		Level:     SWLogger.LogLevel,
-		Formatter: &Formatter{},
+		Out:      os.Stderr,
+		Level:    logger.LogLevel,
+		ExitFunc: logger.exit,
+	}
+	if logger.stdOutput != nil {
+		logger.STDLogger.Out = logger.stdOutput
+	}
+	// init colored stderr logging
+	if logger.colorParts == 0 {
+		logger.colors = DefaultColors
+		logger.colorParts = ColorLevel
+	}
+	logger.STDLogger.Formatter = logger.newSTDFormatter()
+	if logger.stdFormatter != nil {
+		logger.STDLogger.Formatter = logger.stdFormatter
+	}
+
+	for _, sink := range logger.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			logger.closers = append(logger.closers, closer)
+		}
+	}
+
+	if logger.asyncSize > 0 {
+		logger.async = newAsyncQueue(logger, logger.asyncSize, logger.asyncPolicy)
 	}
 
 	// init finished
 	logger.isSetup = true
+
+	if fallbackErr != nil {
+		logger.Warnf("fall back to stderr only logging: %s", fallbackErr.Error())
+	}
+	return nil
+}
+
+// newFileWriter creates the log directory and the rotated log file writer,
+// which is closed along with the logger
+func (logger *SWLog) newFileWriter() (*rotatelogs.RotateLogs, error) {
+	if logger.LogFile == "" {
+		return nil, fmt.Errorf("log file or directory is not set")
+	}
+	// monitor requires the permission of the log directory as `o+rx' and log file as `o+r'
+	dir := filepath.Dir(logger.LogFile)
+	if _, err := os.Stat(dir); err != nil {
+		if !os.IsExist(err) {
+			err := os.MkdirAll(dir, 0777)
+			if err != nil {
+				return nil, fmt.Errorf("fail to mkdir %s; %w", dir, err)
+			}
+		}
+	}
+
+	// init logrotate
+	rotation := logger.rotation
+	if rotation.Clock == nil {
+		rotation.Clock = logger.clock
+	}
+	rotation = rotation.withDefaults()
+	opts := rotation.rotateOptions(logger.LogFile)
+	var compressor *compressor
+	if rotation.Compress {
+		compressor = newCompressor(logger.LogFile+rotation.Pattern, logger.LogFile, rotation)
+		opts = append(opts, rotatelogs.WithHandler(compressor))
+	}
+	writer, err := rotatelogs.New(logger.LogFile+rotation.Pattern, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("config local file system for logger error: %w", err)
+	}
+	// rotatelogs opens the log file lazily, make sure it can be opened now
+	if _, err := writer.Write(nil); err != nil {
+		if cause := createError(dir); cause != nil {
+			err = cause
+		}
+		return nil, fmt.Errorf("open log file %s error: %w", logger.LogFile, err)
+	}
+
+	logger.closers = append(logger.closers, writer)
+	if compressor != nil {
+		// wait for the compressions in background once the writer is closed
+		logger.closers = append(logger.closers, compressor)
+	}
+	return writer, nil
+}
+
+// createError returns the error of creating a file in dir, rotatelogs only
+// keeps the message of the error it gets when opening the log file
+func createError(dir string) error {
+	f, err := os.CreateTemp(dir, ".log-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
 }
 
 func (logger *SWLog) SetRawSTDLogging(isRaw bool) {
@@ -213,70 +364,150 @@ func (logger *SWLog) SetRawSTDLogging(isRaw bool) {
 	if logger.isRaw {
 		logger.STDLogger.SetFormatter(&NoFormatter{})
 	} else {
-		logger.STDLogger.SetFormatter(&Formatter{})
+		logger.STDLogger.SetFormatter(logger.newSTDFormatter())
 	}
 }
 
-func (logger *SWLog) formatterDecorator(filename string, funcname string) {
-	if logger.isRaw {
-		(logger.STDLogger.Formatter).(*NoFormatter).FileName = filename
-		(logger.STDLogger.Formatter).(*NoFormatter).FuncName = funcname
-	} else {
-		(logger.STDLogger.Formatter).(*Formatter).FileName = filename
-		(logger.STDLogger.Formatter).(*Formatter).FuncName = funcname
+// SetFormatter set the formatter of the given outputs, i.e. `&JSONFormatter{}`
+// for the file while keeping the default Formatter for stderr.
+func (logger *SWLog) SetFormatter(output Output, formatter logrus.Formatter) {
+	if output&OutputSTD != 0 {
+		logger.isRaw = false
+		logger.STDLogger.SetFormatter(formatter)
+	}
+	if output&OutputFile != 0 {
+		logger.FileLogger.SetFormatter(formatter)
+	}
+}
+
+// SetLevel set the log level, it may be called while other goroutines are
+// logging
+func (logger *SWLog) SetLevel(level logrus.Level) {
+	if logger.FileLogger == nil {
+		// the level is taken at setup
+		logger.LogLevel = level
+		return
+	}
+
+	// the levels of the logrus loggers are set atomically, they are the
+	// current level once set up, LogLevel is kept in sync
+	logger.levelMu.Lock()
+	defer logger.levelMu.Unlock()
+	logger.FileLogger.SetLevel(level)
+	if logger.STDLogger != nil {
+		logger.STDLogger.SetLevel(level)
 	}
-	(logger.FileLogger.Formatter).(*Formatter).FileName = filename
-	(logger.FileLogger.Formatter).(*Formatter).FuncName = funcname
+	logger.LogLevel = level
+}
+
+// GetLevel get the log level
+func (logger *SWLog) GetLevel() logrus.Level {
+	if logger.FileLogger == nil {
+		return logger.LogLevel
+	}
+	// loggerSTD and loggerF got the same LogLevel
+	return logger.FileLogger.GetLevel()
 }
 
 func (logger *SWLog) isLevelEnabled(level logrus.Level) bool {
-	return logger.LogLevel >= level
+	// the level of the logrus logger is read atomically, the level may be
+	// changed at runtime
+	if logger.FileLogger == nil {
+		return logger.LogLevel >= level
+	}
+	return logger.FileLogger.IsLevelEnabled(level)
 }
 
 // Log logging the message of input args...
 func (logger *SWLog) Log(level logrus.Level, filename string, funcname string, args ...interface{}) {
+	logger.log(context.Background(), level, nil, filename, funcname, args...)
+}
+
+// log logging the message of input args with the given fields into every sink
+func (logger *SWLog) log(ctx context.Context, level logrus.Level, fields logrus.Fields, filename string, funcname string, args ...interface{}) {
 	// SWLog needs to be setup first before logging
 	if !logger.isSetup {
 		logrus.Fatal("log not setup which will cause panic")
 	}
 
-	// decorate log with calling info
-	logger.formatterDecorator(filename, funcname)
+	if !logger.isLevelEnabled(level) {
+		return
+	}
 
-	if logger.isLevelEnabled(level) {
-		switch level {
-		case logrus.PanicLevel:
-			if logger.IsLog2STD {
-				logger.STDLogger.Panic(args...)
-			}
-			logger.FileLogger.Panic(args...)
-		case logrus.FatalLevel:
-			if logger.IsLog2STD {
-				logger.STDLogger.Fatal(args...)
-			}
-			logger.FileLogger.Fatal(args...)
-		case logrus.ErrorLevel:
-			if logger.IsLog2STD {
-				logger.STDLogger.Error(args...)
-			}
-			logger.FileLogger.Error(args...)
-		case logrus.WarnLevel:
-			if logger.IsLog2STD {
-				logger.STDLogger.Warn(args...)
-			}
-			logger.FileLogger.Warn(args...)
-		case logrus.InfoLevel:
-			if logger.IsLog2STD {
-				logger.STDLogger.Info(args...)
-			}
-			logger.FileLogger.Info(args...)
-		default:
-			if logger.IsLog2STD {
-				logger.STDLogger.Debug(args...)
-			}
-			logger.FileLogger.Debug(args...)
+	// the message is built right away, args may be changed after returning
+	// in async mode
+	r := &record{
+		level:   level,
+		fields:  logger.contextFields(ctx, fields),
+		ctx:     withCaller(ctx, filename, funcname),
+		time:    logger.clock.Now(),
+		message: fmt.Sprint(args...),
+	}
+	logger.output(r)
+}
+
+// output writes the record into every sink, or queues it in async mode
+func (logger *SWLog) output(r *record) {
+	if logger.async != nil && logger.async.enqueue(r) {
+		return
+	}
+	logger.write(r)
+}
+
+// record is a log call waiting to be written into the sinks
+type record struct {
+	level   logrus.Level
+	fields  logrus.Fields
+	ctx     context.Context
+	time    time.Time
+	message string
+	// flushed is closed once the records queued before are written
+	flushed chan struct{}
+}
+
+// write writes the record into every sink, the panic and fatal records are
+// written into every sink and flushed before panicking or exiting.
+func (logger *SWLog) write(r *record) {
+	// every sink gets its own entry carrying the calling info, the
+	// formatters are shared between goroutines and must stay stateless
+	fileEntry := logrus.NewEntry(logger.FileLogger).WithFields(r.fields).WithContext(r.ctx).WithTime(r.time)
+	if logger.IsLog2STD {
+		stdEntry := logrus.NewEntry(logger.STDLogger).WithFields(r.fields).WithContext(r.ctx).WithTime(r.time)
+		writeEntry(stdEntry, r.level, r.message)
+	}
+	writeEntry(fileEntry, r.level, r.message)
+	logger.writeSinks(r)
+
+	switch r.level {
+	case logrus.PanicLevel:
+		logger.Sync()
+		// same as the panic value of logrus
+		fileEntry.Level = r.level
+		fileEntry.Message = r.message
+		if logger.panicFunc != nil {
+			logger.panicFunc(fileEntry)
+			return
 		}
+		panic(fileEntry)
+	case logrus.FatalLevel:
+		logger.exit(1)
+	}
+}
+
+// writeEntry logs the entry without panicking in panic level nor exiting in
+// fatal level
+func writeEntry(entry *logrus.Entry, level logrus.Level, message string) {
+	if level == logrus.PanicLevel {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(*logrus.Entry); !ok {
+					panic(r)
+				}
+			}
+		}()
 	}
+	// logrus exits in Logger.Fatal only, Entry.Log just logs in fatal level
+	entry.Log(level, message)
 }
 
 // Logf logging the message with the given formated args
@@ -286,200 +517,73 @@ func (logger *SWLog) Logf(level logrus.Level, format string, filename string, fu
 
 // Debug logging in debug level
 func (logger *SWLog) Debug(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.DebugLevel, filename, funcname, args...)
 }
 
 // Info logging in info level
 func (logger *SWLog) Info(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.InfoLevel, filename, funcname, args...)
 }
 
 // Warn logging in warning level
 func (logger *SWLog) Warn(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.WarnLevel, filename, funcname, args...)
 }
 
 // Error logging in error level
 func (logger *SWLog) Error(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.ErrorLevel, filename, funcname, args...)
 }
 
 // Fatal logging in fatal level and calling the os.Exit()
 func (logger *SWLog) Fatal(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.FatalLevel, filename, funcname, args...)
 }
 
 // Panic logging in panic level and calling the os.Panic()
 func (logger *SWLog) Panic(args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Log(logrus.PanicLevel, filename, funcname, args...)
 }
 
 // Debugf logging in debug level with the given formated args
 func (logger *SWLog) Debugf(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
-
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.DebugLevel, format, filename, funcname, args...)
 }
 
 // Infof logging in info level
 func (logger *SWLog) Infof(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.InfoLevel, format, filename, funcname, args...)
 }
 
 // Warnf logging in warning level
 func (logger *SWLog) Warnf(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.WarnLevel, format, filename, funcname, args...)
 }
 
 // Errorf logging in error level
 func (logger *SWLog) Errorf(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.ErrorLevel, format, filename, funcname, args...)
 }
 
 // Fatalf logging in fatal level and calling the os.Exit()
 func (logger *SWLog) Fatalf(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.FatalLevel, format, filename, funcname, args...)
 }
 
 // Panicf logging in panic level and calling the os.Panic()
 func (logger *SWLog) Panicf(format string, args ...interface{}) {
-	var dir, filename, funcname string
-	var line int
-	pc, filename, line, ok := runtime.Caller(logger.skip)
-	if ok {
-		funcname = runtime.FuncForPC(pc).Name()      // main.(*MyStruct).foo
-		funcname = filepath.Ext(funcname)            // .foo
-		funcname = strings.TrimPrefix(funcname, ".") // foo
-
-		dir, filename = filepath.Split(filename)
-		filename = filepath.Base(dir) + "/" + filepath.Base(filename) + ":" + strconv.FormatInt(int64(line), 10) // /full/path/basename.go => basename.go
-	}
+	filename, funcname := caller(logger.skip)
 	logger.Logf(logrus.PanicLevel, format, filename, funcname, args...)
 }
 
@@ -512,76 +616,97 @@ var (
 
 // SetLogLevel set the log level
 func SetLogLevel(level logrus.Level) {
-	if SWLogger.IsLog2STD {
-		SWLogger.STDLogger.SetLevel(level)
-	}
-
-	SWLogger.FileLogger.SetLevel(level)
-	SWLogger.LogLevel = level
+	SWLogger.SetLevel(level)
 }
 
 // GetLogLevel get the log level
 func GetLogLevel() logrus.Level {
-	// loggerSTD and loggerF got the same LogLevel
-	return SWLogger.FileLogger.GetLevel()
+	return SWLogger.GetLevel()
+}
+
+// WithField creates an entry from SWLogger with a single field
+func WithField(key string, value interface{}) *Entry {
+	return SWLogger.WithField(key, value)
+}
+
+// WithFields creates an entry from SWLogger with multiple fields
+func WithFields(fields logrus.Fields) *Entry {
+	return SWLogger.WithFields(fields)
+}
+
+// WithError creates an entry from SWLogger with the error as the `error` field
+func WithError(err error) *Entry {
+	return SWLogger.WithError(err)
 }
 
 // Debug logging in debug level
 func Debug(args ...interface{}) {
-	SWLogger.Debug(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.DebugLevel, filename, funcname, args...)
 }
 
 // Info logging in info level
 func Info(args ...interface{}) {
-	SWLogger.Info(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.InfoLevel, filename, funcname, args...)
 }
 
 // Warn logging in warning level
 func Warn(args ...interface{}) {
-	SWLogger.Warn(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.WarnLevel, filename, funcname, args...)
 }
 
 // Error logging in error level
 func Error(args ...interface{}) {
-	SWLogger.Error(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.ErrorLevel, filename, funcname, args...)
 }
 
 // Fatal logging in fatal level and calling the os.Exit()
 func Fatal(args ...interface{}) {
-	SWLogger.Fatal(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.FatalLevel, filename, funcname, args...)
 }
 
 // Panic logging in panic level and calling the os.Panic()
 func Panic(args ...interface{}) {
-	SWLogger.Panic(args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Log(logrus.PanicLevel, filename, funcname, args...)
 }
 
 // Debugf logging in debug level with the given formated args
 func Debugf(format string, args ...interface{}) {
-	SWLogger.Debugf(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.DebugLevel, format, filename, funcname, args...)
 }
 
 // Infof logging in info level
 func Infof(format string, args ...interface{}) {
-	SWLogger.Infof(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.InfoLevel, format, filename, funcname, args...)
 }
 
 // Warnf logging in warning level
 func Warnf(format string, args ...interface{}) {
-	SWLogger.Warnf(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.WarnLevel, format, filename, funcname, args...)
 }
 
 // Errorf logging in error level
 func Errorf(format string, args ...interface{}) {
-	SWLogger.Errorf(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.ErrorLevel, format, filename, funcname, args...)
 }
 
 // Fatalf logging in fatal level and calling the os.Exit()
 func Fatalf(format string, args ...interface{}) {
-	SWLogger.Fatalf(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.FatalLevel, format, filename, funcname, args...)
 }
 
 // Panicf logging in panic level and calling the os.Panic()
 func Panicf(format string, args ...interface{}) {
-	SWLogger.Panicf(format, args...)
+	filename, funcname := caller(SWLogger.skip)
+	SWLogger.Logf(logrus.PanicLevel, format, filename, funcname, args...)
 }
@@ -2,9 +2,14 @@ package log
 
 // unit case for log
 import (
+	"bytes"
+	"errors"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -32,10 +37,6 @@ func TestLog(t *testing.T) {
 	SWLogger.Init(testLogFile, logrus.DebugLevel, true)
 	SWLogger.Log(logrus.DebugLevel, testLogFile, "test log %s", "test")
 }
-func TestFormatterDecorator(t *testing.T) {
-	SWLogger.Init(testLogFile, logrus.DebugLevel, true)
-	SWLogger.formatterDecorator(testLogFile, "test")
-}
 func TestDebug(t *testing.T) {
 	SWLogger.Init(testLogFile, logrus.DebugLevel, true)
 	Debug("test debug")
@@ -53,16 +54,35 @@ func TestError(t *testing.T) {
 	Error("test error")
 }
 
+// useExitingLogger replaces SWLogger by a logger writing into the returned
+// buffer, whose exit function records the exit codes instead of exiting
+func useExitingLogger(t *testing.T) (*bytes.Buffer, *[]int) {
+	var buf bytes.Buffer
+	var codes []int
+	logger, err := New(
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+		WithExitFunc(func(code int) { codes = append(codes, code) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := SWLogger
+	SWLogger = logger
+	t.Cleanup(func() {
+		SWLogger = previous
+	})
+	return &buf, &codes
+}
+
 func TestFatal(t *testing.T) {
-	if os.Getenv("CRASH") == "1" {
-		Fatal("Fatal test")
-		return
+	buf, codes := useExitingLogger(t)
+	Fatal("Fatal test")
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("unexpected exit codes %v", *codes)
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestFatal")
-	cmd.Env = append(os.Environ(), "CRASH=1")
-	err := cmd.Run()
-	if e, ok := err.(*exec.ExitError); ok && e.Success() {
-		t.Fatal("process ran with success which is not expected")
+	if buf.String() != "FATAL  |Fatal test\n" {
+		t.Fatalf("unexpected output %q", buf.String())
 	}
 }
 
@@ -97,15 +117,13 @@ func TestWarnf(t *testing.T) {
 }
 
 func TestFatalf(t *testing.T) {
-	if os.Getenv("CRASH") == "1" {
-		Fatalf("%s test", "Fatalf")
-		return
+	buf, codes := useExitingLogger(t)
+	Fatalf("%s test", "Fatalf")
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("unexpected exit codes %v", *codes)
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestFatalf")
-	cmd.Env = append(os.Environ(), "CRASH=1")
-	err := cmd.Run()
-	if e, ok := err.(*exec.ExitError); ok && e.Success() {
-		t.Fatal("process ran with success which is not expected")
+	if buf.String() != "FATAL  |Fatalf test\n" {
+		t.Fatalf("unexpected output %q", buf.String())
 	}
 }
 
@@ -121,6 +139,9 @@ func TestPanicf(t *testing.T) {
 func TestSetLogLevel(t *testing.T) {
 	SWLogger.Init(testLogFile, logrus.DebugLevel, true)
 	SetLogLevel(logrus.InfoLevel)
+	if SWLogger.LogLevel != logrus.InfoLevel {
+		t.Fatalf("got LogLevel %v", SWLogger.LogLevel)
+	}
 }
 
 func TestGetLogLevel(t *testing.T) {
@@ -138,3 +159,150 @@ func TestSetRawSTDLogging(t *testing.T) {
 
 	SWLogger.Infof("test %s and %f in STD", "raw logging", 1.223)
 }
+
+func TestInitE(t *testing.T) {
+	logger := &SWLog{}
+	if err := logger.InitE("", logrus.DebugLevel, false); err == nil {
+		t.Error("expect an error for an empty log file")
+	}
+
+	// the log directory can not be created under a regular file
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	logger = &SWLog{}
+	err := logger.InitE(filepath.Join(notDir, "sub", "test.log"), logrus.DebugLevel, false)
+	if err == nil || !strings.Contains(err.Error(), "fail to mkdir") {
+		t.Fatalf("expect a mkdir error, got %v", err)
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || !errors.Is(err, syscall.ENOTDIR) {
+		t.Fatalf("the mkdir error %v is not wrapped", err)
+	}
+
+	// the log file can not be opened in a regular file
+	logger = &SWLog{}
+	err = logger.InitE(filepath.Join(notDir, "test.log"), logrus.DebugLevel, false)
+	if err == nil || !strings.Contains(err.Error(), "open log file") {
+		t.Fatalf("expect an open error, got %v", err)
+	}
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("the open error %v is not wrapped", err)
+	}
+
+	logger = &SWLog{}
+	if err := logger.InitE(filepath.Join(t.TempDir(), "test.log"), logrus.DebugLevel, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.InitE("", logrus.DebugLevel, false); err != nil {
+		t.Fatalf("InitE of a setup logger failed: %s", err)
+	}
+}
+
+func TestInitEPermission(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root writes into read-only directories")
+	}
+	dir := filepath.Join(t.TempDir(), "readonly")
+	if err := os.Mkdir(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &SWLog{}
+	err := logger.InitE(filepath.Join(dir, "test.log"), logrus.DebugLevel, false)
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("expect a permission error, got %v", err)
+	}
+	err = logger.InitE(filepath.Join(dir, "sub", "test.log"), logrus.DebugLevel, false)
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("expect a permission error, got %v", err)
+	}
+}
+
+func TestInitESTDFallback(t *testing.T) {
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := &SWLog{}
+	err := logger.InitE(filepath.Join(notDir, "test.log"), logrus.InfoLevel, false,
+		WithSTDFallback(true), WithSTDOutput(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logger.IsLog2STD {
+		t.Fatal("logger does not log into stderr")
+	}
+	logger.Info("still logging")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "fall back to stderr only logging") ||
+		!strings.HasSuffix(lines[1], "|still logging") {
+		t.Fatalf("unexpected stderr output %q", buf.String())
+	}
+}
+
+// runCrashingLogger runs the test in a subprocess which logs into the returned
+// log file and stderr before crashing
+func runCrashingLogger(t *testing.T, name string) (string, string) {
+	logFile := filepath.Join(t.TempDir(), "crash.log")
+	var stderr bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^"+name+"$")
+	cmd.Env = append(os.Environ(), "CRASH_LOG_FILE="+logFile)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); !ok || e.Success() {
+		t.Fatalf("process ran with %v which is not expected", err)
+	}
+
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), stderr.String()
+}
+
+func newCrashingLogger(t *testing.T, logFile string) *SWLog {
+	logger, err := New(
+		WithLogFile(logFile),
+		WithLog2STD(true),
+		WithFormatter(OutputBoth, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func TestFatalAllSinks(t *testing.T) {
+	if logFile := os.Getenv("CRASH_LOG_FILE"); logFile != "" {
+		newCrashingLogger(t, logFile).Fatalf("%s test", "Fatalf")
+		return
+	}
+
+	content, stderr := runCrashingLogger(t, "TestFatalAllSinks")
+	if content != "FATAL  |Fatalf test\n" {
+		t.Errorf("unexpected log file content %q", content)
+	}
+	if !strings.Contains(stderr, "FATAL  |Fatalf test\n") {
+		t.Errorf("unexpected stderr %q", stderr)
+	}
+}
+
+func TestPanicAllSinks(t *testing.T) {
+	if logFile := os.Getenv("CRASH_LOG_FILE"); logFile != "" {
+		newCrashingLogger(t, logFile).Panic("Panic test")
+		return
+	}
+
+	content, stderr := runCrashingLogger(t, "TestPanicAllSinks")
+	if content != "PANIC  |Panic test\n" {
+		t.Errorf("unexpected log file content %q", content)
+	}
+	if !strings.Contains(stderr, "PANIC  |Panic test\n") {
+		t.Errorf("unexpected stderr %q", stderr)
+	}
+}
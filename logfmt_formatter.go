@@ -0,0 +1,116 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogfmtFormatter implements logrus.Formatter interface, it outputs one
+// logfmt line per entry, i.e.
+// `time=2021-03-09T10:16:18Z level=info caller=pkg/file.go:12 func=foo msg="hello world" key=value`.
+// The keys are the same as JSONFormatter: time, level, caller, func and msg
+// first, followed by all the entry fields sorted by key.
+type LogfmtFormatter struct {
+	// Timestamp format, time.RFC3339Nano by default
+	TimestampFormat string
+}
+
+// Format building log message.
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339Nano
+	}
+	filename, funcname := EntryCaller(entry)
+
+	buf := &bytes.Buffer{}
+	writeLogfmtPair(buf, JSONKeyTime, entry.Time.Format(timestampFormat))
+	writeLogfmtPair(buf, JSONKeyLevel, entry.Level.String())
+	writeLogfmtPair(buf, JSONKeyCaller, filename)
+	writeLogfmtPair(buf, JSONKeyFunc, funcname)
+	writeLogfmtPair(buf, JSONKeyMsg, entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := k
+		switch k {
+		case JSONKeyTime, JSONKeyLevel, JSONKeyCaller, JSONKeyFunc, JSONKeyMsg:
+			key = "fields." + k
+		}
+		writeLogfmtPair(buf, key, logfmtValue(entry.Data[k]))
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// logfmtValue converts any field value into its string form
+func logfmtValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		// before fmt.Stringer which time.Time implements
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeLogfmtPair writes `key=value` into buf, the value is quoted if needed
+func writeLogfmtPair(buf *bytes.Buffer, key string, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	if needsLogfmtQuoting(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// logfmtKey replaces the characters which are not allowed in a logfmt key
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// needsLogfmtQuoting reports whether the value has to be quoted
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
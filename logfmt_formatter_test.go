@@ -0,0 +1,39 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"user":    "alice",
+		"id":      7,
+		"ok":      true,
+		"error":   errors.New("file not found"),
+		"path":    `C:\tmp`,
+		"empty":   "",
+		"latency": 1500 * time.Millisecond,
+		"started": time.Date(2021, 3, 9, 10, 16, 17, 500000000, time.UTC),
+		"level":   "clash",
+		"bad key": "a=b",
+	}).WithContext(withCaller(context.Background(), "log/logfmt_formatter_test.go:1", "TestLogfmtFormatter"))
+	entry.Time = time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = "hello \"logfmt\"\nsecond line"
+
+	b, err := (&LogfmtFormatter{}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `time=2021-03-09T10:16:18Z level=warning caller=log/logfmt_formatter_test.go:1 func=TestLogfmtFormatter ` +
+		`msg="hello \"logfmt\"\nsecond line" bad_key="a=b" empty= error="file not found" id=7 latency=1.5s ` +
+		`fields.level=clash ok=true path="C:\\tmp" started=2021-03-09T10:16:17.5Z user=alice` + "\n"
+	if string(b) != want {
+		t.Fatalf("got  %s\nwant %s", b, want)
+	}
+}
@@ -0,0 +1,311 @@
+// Package loggrpc provides the gRPC interceptors logging each call with the log
+// package.
+package loggrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// field names of the call entries
+const (
+	MethodKey        = "method"
+	CodeKey          = "code"
+	DurationKey      = "duration_ms"
+	PeerKey          = "peer"
+	SentBytesKey     = "sent_bytes"
+	ReceivedBytesKey = "received_bytes"
+)
+
+// DefaultCodeLevel returns the log level of the calls ending with the code: the
+// client errors are logged in warning level and the server errors in error
+// level.
+func DefaultCodeLevel(code codes.Code) logrus.Level {
+	switch code {
+	case codes.OK:
+		return logrus.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// Option configures the interceptors
+type Option func(c *config)
+
+type config struct {
+	codeLevel    func(code codes.Code) logrus.Level
+	payloadSizes bool
+	// calling info of the interceptor constructor
+	filename string
+	funcname string
+}
+
+// WithCodeLevel sets the log level of the calls ending with each code,
+// DefaultCodeLevel by default
+func WithCodeLevel(codeLevel func(code codes.Code) logrus.Level) Option {
+	return func(c *config) {
+		c.codeLevel = codeLevel
+	}
+}
+
+// WithPayloadSizes logs the protobuf sizes of the sent and the received
+// messages as the `sent_bytes` and `received_bytes` fields
+func WithPayloadSizes() Option {
+	return func(c *config) {
+		c.payloadSizes = true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{codeLevel: DefaultCodeLevel}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// the entries have the calling info of the interceptor constructor
+	c.filename, c.funcname = log.Caller(2)
+	return c
+}
+
+// UnaryServerInterceptor returns a server interceptor logging each unary call
+// with the `method`, `code`, `duration_ms` and `peer` fields. The handlers get
+// the request-scoped logger with the `method` and `peer` fields by
+// log.FromContext.
+func UnaryServerInterceptor(logger *log.SWLog, opts ...Option) grpc.UnaryServerInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := logger.Clock().Now()
+		ctx = logger.NewContext(ctx, logrus.Fields{MethodKey: info.FullMethod, PeerKey: peerAddr(ctx)})
+		resp, err := handler(ctx, req)
+
+		fields := logrus.Fields{}
+		if c.payloadSizes {
+			fields[ReceivedBytesKey] = messageSize(req)
+			if err == nil {
+				fields[SentBytesKey] = messageSize(resp)
+			}
+		}
+		c.log(logger.FromContext(ctx), "finished call", info.FullMethod, logger.Clock().Now().Sub(start), err, fields)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor logging each stream
+// call like UnaryServerInterceptor, the payload sizes are the total sizes of
+// the messages.
+func StreamServerInterceptor(logger *log.SWLog, opts ...Option) grpc.StreamServerInterceptor {
+	c := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := logger.Clock().Now()
+		ctx := logger.NewContext(ss.Context(), logrus.Fields{MethodKey: info.FullMethod, PeerKey: peerAddr(ss.Context())})
+		stream := &serverStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, stream)
+
+		fields := logrus.Fields{}
+		if c.payloadSizes {
+			fields[SentBytesKey] = stream.sent
+			fields[ReceivedBytesKey] = stream.received
+		}
+		c.log(logger.FromContext(ctx), "finished call", info.FullMethod, logger.Clock().Now().Sub(start), err, fields)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor logging each unary call
+// with the `method`, `code`, `duration_ms` and `peer` fields
+func UnaryClientInterceptor(logger *log.SWLog, opts ...Option) grpc.UnaryClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := logger.Clock().Now()
+		p := &peer.Peer{}
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(p))...)
+
+		fields := logrus.Fields{MethodKey: method, PeerKey: addrString(p)}
+		if c.payloadSizes {
+			fields[SentBytesKey] = messageSize(req)
+			if err == nil {
+				fields[ReceivedBytesKey] = messageSize(reply)
+			}
+		}
+		c.log(clientEntry(logger, ctx), "finished client call", method, logger.Clock().Now().Sub(start), err, fields)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor logging each stream
+// call once it ends, when receiving fails, the single response of a client
+// stream is received or the context is done.
+func StreamClientInterceptor(logger *log.SWLog, opts ...Option) grpc.StreamClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := logger.Clock().Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		stream := &clientStream{ClientStream: cs, desc: desc, done: make(chan struct{})}
+		// the peer call option is set concurrently with the cancellation, the
+		// peer of the stream context is set once created
+		var addr string
+		if cs != nil {
+			addr = peerAddr(cs.Context())
+		}
+		stream.finish = func(err error) {
+			fields := logrus.Fields{MethodKey: method, PeerKey: addr}
+			if c.payloadSizes {
+				fields[SentBytesKey] = stream.sent
+				fields[ReceivedBytesKey] = stream.received
+			}
+			c.log(clientEntry(logger, ctx), "finished client call", method, logger.Clock().Now().Sub(start), err, fields)
+		}
+		if err != nil {
+			stream.end(err)
+			return nil, err
+		}
+		// the streams abandoned before receiving till the end are logged once
+		// cancelled
+		go func() {
+			select {
+			case <-ctx.Done():
+				stream.end(status.FromContextError(ctx.Err()).Err())
+			case <-stream.done:
+			}
+		}()
+		return stream, nil
+	}
+}
+
+// clientEntry returns the entry of the client calls, the fields of the
+// request-scoped logger of ctx are left out as they describe the inbound call,
+// i.e. its method and peer, while the context values are still logged
+func clientEntry(logger *log.SWLog, ctx context.Context) *log.Entry {
+	return logger.WithFields(nil).WithContext(ctx)
+}
+
+// log logs the call ending with err after duration
+func (c *config) log(entry *log.Entry, message string, method string, duration time.Duration, err error, fields logrus.Fields) {
+	code := status.Code(err)
+	fields[CodeKey] = code.String()
+	fields[DurationKey] = float64(duration) / float64(time.Millisecond)
+	entry = entry.WithFields(fields)
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Log(c.codeLevel(code), c.filename, c.funcname, fmt.Sprintf("%s %s %s", message, method, code))
+}
+
+// serverStream carries the request-scoped logger and counts the payload sizes
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     int
+	received int
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent += messageSize(m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received += messageSize(m)
+	}
+	return err
+}
+
+// clientStream logs the call once it ends and counts the payload sizes
+type clientStream struct {
+	grpc.ClientStream
+	desc   *grpc.StreamDesc
+	finish func(err error)
+	once   sync.Once
+	// done is closed once the call is logged
+	done chan struct{}
+
+	mu       sync.Mutex
+	sent     int
+	received int
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.sent += messageSize(m)
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.end(nil)
+		} else {
+			s.end(err)
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	s.received += messageSize(m)
+	s.mu.Unlock()
+	// the call ends with the single response of a client stream
+	if !s.desc.ServerStreams {
+		s.end(nil)
+	}
+	return nil
+}
+
+// end logs the call once
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.finish(err)
+		close(s.done)
+	})
+}
+
+// messageSize returns the protobuf size of the message, 0 for other messages
+func messageSize(m interface{}) int {
+	if m, ok := m.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+// peerAddr returns the address of the peer of the server call
+func peerAddr(ctx context.Context) string {
+	p, _ := peer.FromContext(ctx)
+	return addrString(p)
+}
+
+func addrString(p *peer.Peer) string {
+	if p == nil || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
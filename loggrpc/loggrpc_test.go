@@ -0,0 +1,186 @@
+package loggrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer logs with the request-scoped logger of the handler contexts
+type healthServer struct {
+	*health.Server
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	log.FromContext(ctx).Infof("checking %q", req.Service)
+	return s.Server.Check(ctx, req)
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	log.FromContext(stream.Context()).Infof("watching %q", req.Service)
+	return s.Server.Watch(req, stream)
+}
+
+// startServer serves the health service over bufconn, the returned client logs
+// into the client logger
+func startServer(t *testing.T, server, client *logtest.Logger, opts ...Option) healthpb.HealthClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(server.SWLog, opts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(server.SWLog, opts...)),
+	)
+	healthpb.RegisterHealthServer(s, &healthServer{health.NewServer()})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(client.SWLog, opts...)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(client.SWLog, opts...)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return healthpb.NewHealthClient(conn)
+}
+
+// waitEntries waits for the entries logged after the call returned
+func waitEntries(t *testing.T, l *logtest.Logger, n int) []logtest.Entry {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries := l.Entries()
+		if len(entries) >= n || time.Now().After(deadline) {
+			if len(entries) != n {
+				t.Fatalf("got %d entries, want %d: %+v", len(entries), n, entries)
+			}
+			return entries
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	// the durations are measured by the clock of the loggers
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	server := logtest.New(t, logtest.WithLogOptions(log.WithClock(clock)))
+	client := logtest.New(t, logtest.WithLogOptions(log.WithClock(clock)))
+	hc := startServer(t, server, client, WithPayloadSizes())
+
+	// the fields of an inbound call are not logged with the outbound calls
+	ctx := client.NewContext(context.Background(), logrus.Fields{MethodKey: "/inbound", "user": "alice"})
+	if _, err := hc.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := hc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("got error %v, want NotFound", err)
+	}
+
+	const method = "/grpc.health.v1.Health/Check"
+	entries := waitEntries(t, server, 4)
+	handling, call := entries[0], entries[1]
+	if handling.Message != `checking ""` || handling.Data[MethodKey] != method || handling.Data[PeerKey] != "bufconn" {
+		t.Errorf("unexpected handler entry %+v", handling)
+	}
+	if call.Level != logrus.InfoLevel || call.Message != "finished call "+method+" OK" ||
+		call.Data[CodeKey] != "OK" || call.Data[PeerKey] != "bufconn" || call.Data[SentBytesKey] != 2 ||
+		call.Data[ReceivedBytesKey] != 0 || call.FuncName != "startServer" {
+		t.Errorf("unexpected call entry %+v", call)
+	}
+	if call.Data[DurationKey] != 0.0 {
+		t.Errorf("unexpected duration in %+v", call.Data)
+	}
+	if failed := entries[3]; failed.Level != logrus.WarnLevel || failed.Data[CodeKey] != "NotFound" ||
+		failed.Data[ReceivedBytesKey] != 9 || failed.Data[logrus.ErrorKey] == nil {
+		t.Errorf("unexpected failed call entry %+v", failed)
+	}
+
+	entries = waitEntries(t, client, 2)
+	if call := entries[0]; call.Level != logrus.InfoLevel || call.Message != "finished client call "+method+" OK" ||
+		call.Data[MethodKey] != method || call.Data[PeerKey] != "bufconn" || call.Data[SentBytesKey] != 0 ||
+		call.Data[ReceivedBytesKey] != 2 || call.Data["user"] != nil {
+		t.Errorf("unexpected client call entry %+v", call)
+	}
+	if failed := entries[1]; failed.Level != logrus.WarnLevel || failed.Data[CodeKey] != "NotFound" {
+		t.Errorf("unexpected failed client call entry %+v", failed)
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	server, client := logtest.New(t), logtest.New(t)
+	hc := startServer(t, server, client, WithPayloadSizes(), WithCodeLevel(func(code codes.Code) logrus.Level {
+		return logrus.DebugLevel
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := hc.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("got error %v, want Canceled", err)
+	}
+
+	const method = "/grpc.health.v1.Health/Watch"
+	entries := waitEntries(t, server, 2)
+	if handling := entries[0]; handling.Message != `watching ""` || handling.Data[MethodKey] != method ||
+		handling.Data[PeerKey] != "bufconn" {
+		t.Errorf("unexpected handler entry %+v", handling)
+	}
+	if call := entries[1]; call.Level != logrus.DebugLevel || call.Data[CodeKey] != "Canceled" ||
+		call.Data[SentBytesKey] != 2 || call.Data[ReceivedBytesKey] != 0 {
+		t.Errorf("unexpected call entry %+v", call)
+	}
+
+	entries = waitEntries(t, client, 1)
+	if call := entries[0]; call.Level != logrus.DebugLevel || !strings.HasSuffix(call.Message, method+" Canceled") ||
+		call.Data[SentBytesKey] != 0 || call.Data[ReceivedBytesKey] != 2 {
+		t.Errorf("unexpected client call entry %+v", call)
+	}
+}
+
+func TestStreamClientInterceptorCancel(t *testing.T) {
+	server, client := logtest.New(t), logtest.New(t)
+	hc := startServer(t, server, client)
+
+	// the stream is abandoned without receiving the cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := hc.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	entries := waitEntries(t, client, 1)
+	if call := entries[0]; call.Level != logrus.WarnLevel || call.Data[CodeKey] != "Canceled" || call.Data[PeerKey] != "bufconn" ||
+		call.Message != "finished client call /grpc.health.v1.Health/Watch Canceled" {
+		t.Errorf("unexpected client call entry %+v", call)
+	}
+}
@@ -0,0 +1,68 @@
+// Package logotel adds the OpenTelemetry trace and span IDs of the active span
+// to the entries logged with a context by the log package.
+package logotel
+
+import (
+	"context"
+
+	"github.com/edony-ink/log"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey is the field name of the trace ID, `%trace_id%` in the log
+	// format of log.Formatter
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the field name of the span ID, `%span_id%` in the log format
+	// of log.Formatter
+	SpanIDKey = "span_id"
+)
+
+// Fields returns the trace and span IDs of the span of ctx, nil if ctx carries
+// no valid span context. It is a log.ContextExtractor.
+func Fields(ctx context.Context) logrus.Fields {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return logrus.Fields{
+		TraceIDKey: spanContext.TraceID().String(),
+		SpanIDKey:  spanContext.SpanID().String(),
+	}
+}
+
+// WithTraceContext adds the trace and span IDs to the entries logged with a
+// context, i.e. by InfoCtx or FromContext
+func WithTraceContext() log.Option {
+	return log.WithContextExtractor(Fields)
+}
+
+// Formatter wraps a formatter such as log.Formatter, the `%trace_id%` and
+// `%span_id%` tokens of the entries logged outside spans are replaced by empty
+// strings.
+type Formatter struct {
+	logrus.Formatter
+}
+
+// Format building log message.
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	_, hasTraceID := entry.Data[TraceIDKey]
+	_, hasSpanID := entry.Data[SpanIDKey]
+	if hasTraceID && hasSpanID {
+		return f.Formatter.Format(entry)
+	}
+
+	fields := logrus.Fields{TraceIDKey: "", SpanIDKey: ""}
+	if entry.Context != nil {
+		for k, v := range Fields(entry.Context) {
+			fields[k] = v
+		}
+	}
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+	formatted := *entry
+	formatted.Data = fields
+	return f.Formatter.Format(&formatted)
+}
@@ -0,0 +1,91 @@
+package logotel
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/edony-ink/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracer(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+	return provider, exporter
+}
+
+func TestWithTraceContext(t *testing.T) {
+	provider, exporter := newTracer(t)
+	var buf bytes.Buffer
+	logger, err := log.New(
+		log.WithOutput(&buf),
+		log.WithFormatter(log.OutputFile, &log.LogfmtFormatter{}),
+		WithTraceContext(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	logger.InfoCtx(ctx, "inside")
+	logger.FromContext(ctx).Warn("request-scoped")
+	span.End()
+	logger.InfoCtx(context.Background(), "outside")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	ids := "span_id=" + spans[0].SpanContext.SpanID().String() + " trace_id=" + spans[0].SpanContext.TraceID().String()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "msg=inside "+ids) {
+		t.Errorf("got line %q, want the IDs %q", lines[0], ids)
+	}
+	if !strings.HasSuffix(lines[1], "msg=request-scoped "+ids) {
+		t.Errorf("got line %q, want the IDs %q", lines[1], ids)
+	}
+	if strings.Contains(lines[2], "trace_id") {
+		t.Errorf("got trace ID outside span in %q", lines[2])
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	provider, _ := newTracer(t)
+	var buf bytes.Buffer
+	logger, err := log.New(
+		log.WithOutput(&buf),
+		log.WithFormatter(log.OutputFile, &Formatter{&log.Formatter{LogFormat: "%lvl%|%trace_id%|%span_id%|%msg%\n"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+	// the IDs come from the context of the entry without WithTraceContext
+	logger.FromContext(ctx).Info("inside")
+	logger.Info("outside")
+
+	spanContext := span.SpanContext()
+	want := "INFO   |" + spanContext.TraceID().String() + "|" + spanContext.SpanID().String() + "|inside\n" +
+		"INFO   |||outside\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFields(t *testing.T) {
+	if fields := Fields(context.Background()); fields != nil {
+		t.Fatalf("got fields %v without span", fields)
+	}
+}
@@ -0,0 +1,22 @@
+package logtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC)
+	clock := NewFakeClock(start)
+	if !clock.Now().Equal(start) {
+		t.Fatalf("Now = %s, want %s", clock.Now(), start)
+	}
+	clock.Add(time.Hour)
+	if want := start.Add(time.Hour); !clock.Now().Equal(want) {
+		t.Fatalf("Now = %s, want %s", clock.Now(), want)
+	}
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Fatalf("Now = %s, want %s", clock.Now(), start)
+	}
+}
@@ -0,0 +1,196 @@
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a captured log entry
+type Entry struct {
+	Level   logrus.Level
+	Message string
+	Data    logrus.Fields
+	Time    time.Time
+	// file name and line number where calling the LOG/INFO/DEBUG...
+	FileName string
+	// function name where calling the LOG/INFO/DEBUG...
+	FuncName string
+	// Line is the formatted log line without the trailing newline
+	Line string
+}
+
+// Logger is a log.SWLog capturing its entries in memory instead of writing a
+// log file
+type Logger struct {
+	*log.SWLog
+
+	t         testing.TB
+	testLog   bool
+	formatter logrus.Formatter
+	logOpts   []log.Option
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Option configures the Logger created by New
+type Option func(l *Logger)
+
+// WithTestLog forwards each log line to t.Log, so that it shows up under the
+// test which logged it
+func WithTestLog() Option {
+	return func(l *Logger) {
+		l.testLog = true
+	}
+}
+
+// WithFormatter sets the formatter of the captured lines, log.Formatter by
+// default
+func WithFormatter(formatter logrus.Formatter) Option {
+	return func(l *Logger) {
+		l.formatter = formatter
+	}
+}
+
+// WithLogOptions sets the options of the underlying log.SWLog, i.e. the level
+// which is debug by default
+func WithLogOptions(opts ...log.Option) Option {
+	return func(l *Logger) {
+		l.logOpts = append(l.logOpts, opts...)
+	}
+}
+
+// New creates a Logger capturing the entries in memory, it is closed when the
+// test finishes.
+func New(t testing.TB, opts ...Option) *Logger {
+	t.Helper()
+	l := &Logger{
+		t:         t,
+		formatter: &log.Formatter{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	logOpts := append([]log.Option{log.WithLevel(logrus.DebugLevel)}, l.logOpts...)
+	logOpts = append(logOpts,
+		log.WithOutput(writerFunc(l.write)),
+		log.WithFormatter(log.OutputFile, &captureFormatter{logger: l, formatter: l.formatter}),
+	)
+	logger, err := log.New(logOpts...)
+	if err != nil {
+		t.Fatalf("create the capture logger error: %s", err.Error())
+	}
+	l.SWLog = logger
+	t.Cleanup(func() {
+		logger.Close()
+	})
+	return l
+}
+
+// Entries returns a copy of the captured entries
+func (l *Logger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]Entry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// Reset drops the captured entries
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
+
+// Logged reports whether an entry of the level with the substring in its
+// message has been captured
+func (l *Logger) Logged(level logrus.Level, substring string) bool {
+	for _, entry := range l.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged fails the test if no entry of the level with the substring in
+// its message has been captured
+func (l *Logger) AssertLogged(t testing.TB, level logrus.Level, substring string) bool {
+	t.Helper()
+	if l.Logged(level, substring) {
+		return true
+	}
+
+	var lines []string
+	for _, entry := range l.Entries() {
+		lines = append(lines, entry.Line)
+	}
+	t.Errorf("no %s entry containing %q logged, got:\n%s", level, substring, strings.Join(lines, "\n"))
+	return false
+}
+
+// AssertNotLogged fails the test if an entry of the level with the substring
+// in its message has been captured
+func (l *Logger) AssertNotLogged(t testing.TB, level logrus.Level, substring string) bool {
+	t.Helper()
+	if !l.Logged(level, substring) {
+		return true
+	}
+	t.Errorf("unexpected %s entry containing %q logged", level, substring)
+	return false
+}
+
+// write forwards the formatted line to the test log if asked to
+func (l *Logger) write(p []byte) (int, error) {
+	if l.testLog {
+		l.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// captureFormatter records every formatted entry into the Logger
+type captureFormatter struct {
+	logger    *Logger
+	formatter logrus.Formatter
+}
+
+// Format building log message.
+func (f *captureFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	filename, funcname := log.EntryCaller(entry)
+
+	f.logger.mu.Lock()
+	defer f.logger.mu.Unlock()
+	f.logger.entries = append(f.logger.entries, Entry{
+		Level:    entry.Level,
+		Message:  entry.Message,
+		Data:     data,
+		Time:     entry.Time,
+		FileName: filename,
+		FuncName: funcname,
+		Line:     strings.TrimSuffix(string(b), "\n"),
+	})
+	return b, nil
+}
+
+// writerFunc turns a function into an io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
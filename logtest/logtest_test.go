@@ -0,0 +1,95 @@
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/sirupsen/logrus"
+)
+
+// recorderTB records the logs and errors of a test
+type recorderTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (r *recorderTB) Helper() {}
+
+func (r *recorderTB) Log(args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprint(args...))
+}
+
+func (r *recorderTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	l := New(t)
+	l.Debug("debug message")
+	l.WithError(errors.New("boom")).Errorf("failed to %s", "connect")
+
+	entries := l.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Level != logrus.DebugLevel || entries[0].Message != "debug message" ||
+		entries[0].FuncName != "TestLogger" || !strings.HasSuffix(entries[0].FileName, "/logtest_test.go:33") {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+	if entries[1].Data[logrus.ErrorKey].(error).Error() != "boom" ||
+		!strings.HasSuffix(entries[1].Line, "|failed to connect") {
+		t.Errorf("unexpected entry %+v", entries[1])
+	}
+
+	l.AssertLogged(t, logrus.ErrorLevel, "connect")
+	l.AssertNotLogged(t, logrus.InfoLevel, "connect")
+
+	l.Reset()
+	if len(l.Entries()) != 0 {
+		t.Fatal("Reset kept the entries")
+	}
+}
+
+func TestLoggerAssertLogged(t *testing.T) {
+	l := New(t)
+	l.Info("hello")
+
+	r := &recorderTB{}
+	if l.AssertLogged(r, logrus.ErrorLevel, "hello") {
+		t.Fatal("AssertLogged passed for a wrong level")
+	}
+	if l.AssertLogged(r, logrus.InfoLevel, "bye") {
+		t.Fatal("AssertLogged passed for a wrong message")
+	}
+	if len(r.errors) != 2 || !strings.Contains(r.errors[0], "|hello") {
+		t.Fatalf("unexpected errors %q", r.errors)
+	}
+	if !l.AssertLogged(r, logrus.InfoLevel, "hell") || len(r.errors) != 2 {
+		t.Fatal("AssertLogged failed")
+	}
+}
+
+func TestLoggerOptions(t *testing.T) {
+	r := &recorderTB{}
+	r.TB = t
+	clock := NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	l := New(r,
+		WithTestLog(),
+		WithFormatter(&log.Formatter{LogFormat: "%time%|%lvl%|%msg%\n"}),
+		WithLogOptions(log.WithLevel(logrus.InfoLevel), log.WithClock(clock)),
+	)
+	l.Debug("filtered")
+	l.Info("forwarded")
+
+	if len(r.logs) != 1 || r.logs[0] != "2021-03-09 10:16:18.000|INFO   |forwarded" {
+		t.Fatalf("unexpected test logs %q", r.logs)
+	}
+	if entries := l.Entries(); len(entries) != 1 || !entries[0].Time.Equal(clock.Now()) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
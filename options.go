@@ -0,0 +1,171 @@
+package log
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Option configures the SWLog created by New or setup by SWLog.InitE
+type Option func(logger *SWLog)
+
+// New creates a SWLog which is independent of SWLogger and the other
+// instances. By default it logs into `DefaultLogDir/DefaultLogFile` in info
+// level without logging into stderr.
+func New(opts ...Option) (*SWLog, error) {
+	logger := &SWLog{
+		LogFile:  filepath.Join(DefaultLogDir, DefaultLogFile),
+		LogLevel: InfoLevel,
+	}
+	for _, opt := range opts {
+		opt(logger)
+	}
+
+	if err := logger.setup(); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+// WithLogFile sets the log file
+func WithLogFile(logFile string) Option {
+	return func(logger *SWLog) {
+		logger.LogFile = logFile
+	}
+}
+
+// WithLevel sets the log level
+func WithLevel(level logrus.Level) Option {
+	return func(logger *SWLog) {
+		logger.LogLevel = level
+	}
+}
+
+// WithLog2STD sets whether to log into stderr as well
+func WithLog2STD(log2STD bool) Option {
+	return func(logger *SWLog) {
+		logger.IsLog2STD = log2STD
+	}
+}
+
+// WithSTDFallback makes the logger fall back to log into stderr only instead
+// of failing if the log file can not be opened.
+func WithSTDFallback(fallback bool) Option {
+	return func(logger *SWLog) {
+		logger.stdFallback = fallback
+	}
+}
+
+// WithRotation sets the rotation policy of the log file
+func WithRotation(rotation RotationConfig) Option {
+	return func(logger *SWLog) {
+		logger.rotation = rotation
+	}
+}
+
+// WithAsync makes the logger write the entries by a background goroutine
+// through a queue of the given size, the policy decides what to do when the
+// queue is full. Call SWLog.Close to write the queued entries before exiting.
+func WithAsync(size int, policy OverflowPolicy) Option {
+	return func(logger *SWLog) {
+		logger.asyncSize = size
+		logger.asyncPolicy = policy
+	}
+}
+
+// WithExitFunc replaces exiting the process after logging in fatal level,
+// i.e. to record the exit code in tests. The logging call returns if fn does.
+func WithExitFunc(fn func(code int)) Option {
+	return func(logger *SWLog) {
+		logger.exitFunc = fn
+	}
+}
+
+// WithPanicFunc replaces panicking after logging in panic level, fn gets the
+// entry logrus would panic with. The logging call returns if fn does.
+func WithPanicFunc(fn func(entry *logrus.Entry)) Option {
+	return func(logger *SWLog) {
+		logger.panicFunc = fn
+	}
+}
+
+// WithClock sets the time source of the entries and the log rotation, i.e. a
+// fake clock to get deterministic timestamps in tests.
+func WithClock(clock Clock) Option {
+	return func(logger *SWLog) {
+		logger.clock = clock
+	}
+}
+
+// WithOutput makes the file logger write into w instead of the rotated log
+// file, no log file or directory is created then.
+func WithOutput(w io.Writer) Option {
+	return func(logger *SWLog) {
+		logger.fileOutput = w
+	}
+}
+
+// WithSTDOutput makes the stderr logger write into w instead of stderr
+func WithSTDOutput(w io.Writer) Option {
+	return func(logger *SWLog) {
+		logger.stdOutput = w
+	}
+}
+
+// WithFormatter sets the formatter of the given outputs
+func WithFormatter(output Output, formatter logrus.Formatter) Option {
+	return func(logger *SWLog) {
+		if output&OutputSTD != 0 {
+			logger.stdFormatter = formatter
+		}
+		if output&OutputFile != 0 {
+			logger.fileFormatter = formatter
+		}
+	}
+}
+
+// WithColors sets the color of each log level and the colored parts of the
+// stderr log line, see SWLog.SetColors. Nil colors turn the colors off.
+func WithColors(colors map[logrus.Level]ColorFormat, parts ColorPart) Option {
+	if parts == 0 {
+		parts = ColorLevel
+	}
+	return func(logger *SWLog) {
+		logger.colors = colors
+		logger.colorParts = parts
+	}
+}
+
+// WithContextExtractor adds the fields returned by extractor for the context
+// of each entry logged with a context, i.e. by InfoCtx or FromContext
+func WithContextExtractor(extractor ContextExtractor) Option {
+	return func(logger *SWLog) {
+		logger.ctxExtractors = append(logger.ctxExtractors, extractor)
+	}
+}
+
+// WithContextKey adds the value of the context key as the field to each entry
+// logged with a context carrying the key, i.e. the request ID
+func WithContextKey(key interface{}, field string) Option {
+	return WithContextExtractor(func(ctx context.Context) logrus.Fields {
+		if value := ctx.Value(key); value != nil {
+			return logrus.Fields{field: value}
+		}
+		return nil
+	})
+}
+
+// WithSink adds a sink receiving every logged entry, i.e. NewSyslogSink
+func WithSink(sink Sink) Option {
+	return func(logger *SWLog) {
+		logger.sinks = append(logger.sinks, sink)
+	}
+}
+
+// WithoutLogFile makes the file logger discard the entries, no log file or
+// directory is created then, i.e. when a sink replaces the log file
+func WithoutLogFile() Option {
+	return WithOutput(io.Discard)
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewIsolated(t *testing.T) {
+	for i, level := range []logrus.Level{logrus.DebugLevel, logrus.WarnLevel} {
+		i, level := i, level
+		t.Run(fmt.Sprintf("logger%d", i), func(t *testing.T) {
+			t.Parallel()
+			var fileBuf, stdBuf bytes.Buffer
+			logger, err := New(
+				WithLevel(level),
+				WithLog2STD(true),
+				WithOutput(&fileBuf),
+				WithSTDOutput(&stdBuf),
+				WithFormatter(OutputBoth, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Debugf("debug %d", i)
+			logger.Warnf("warn %d", i)
+
+			want := fmt.Sprintf("WARNING|warn %d\n", i)
+			if level == logrus.DebugLevel {
+				want = fmt.Sprintf("DEBUG  |debug %d\n", i) + want
+			}
+			if fileBuf.String() != want || stdBuf.String() != want {
+				t.Fatalf("got %q and %q, want %q", fileBuf.String(), stdBuf.String(), want)
+			}
+		})
+	}
+}
+
+func TestNewLogFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "sub", "new.log")
+	logger, err := New(WithLogFile(logFile), WithFormatter(OutputFile, &Formatter{LogFormat: "%func%|%msg%\n"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("into the file")
+
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "TestNewLogFile|into the file\n" {
+		t.Fatalf("unexpected log file content %q", b)
+	}
+	if SWLogger == logger || logger.GetLevel() != logrus.InfoLevel {
+		t.Fatal("unexpected default logger")
+	}
+}
+
+func TestNewError(t *testing.T) {
+	if _, err := New(WithLogFile("")); err == nil {
+		t.Error("expect an error for an empty log file")
+	}
+
+	// the log directory can not be created under a regular file
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := New(WithLogFile(filepath.Join(notDir, "sub", "new.log")))
+	if err == nil || !strings.Contains(err.Error(), "fail to mkdir") {
+		t.Errorf("expect a mkdir error, got %v", err)
+	}
+}
+
+func TestWithExitFunc(t *testing.T) {
+	var buf bytes.Buffer
+	var codes []int
+	logger, err := New(
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+		WithExitFunc(func(code int) { codes = append(codes, code) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Fatalf("%s test", "Fatalf")
+	logger.WithField("user", "alice").Fatal("Fatal test")
+	if len(codes) != 2 || codes[0] != 1 || codes[1] != 1 {
+		t.Fatalf("unexpected exit codes %v", codes)
+	}
+	if buf.String() != "FATAL  |Fatalf test\nFATAL  |Fatal test\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestWithPanicFunc(t *testing.T) {
+	var buf bytes.Buffer
+	var entries []*logrus.Entry
+	logger, err := New(
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &Formatter{LogFormat: "%lvl%|%msg%\n"}),
+		WithPanicFunc(func(entry *logrus.Entry) { entries = append(entries, entry) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.WithField("user", "alice").Panicf("%s test", "Panicf")
+	if len(entries) != 1 || entries[0].Message != "Panicf test" || entries[0].Level != logrus.PanicLevel ||
+		entries[0].Data["user"] != "alice" {
+		t.Fatalf("unexpected panic entries %v", entries)
+	}
+	if buf.String() != "PANIC  |Panicf test\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+)
+
+// RotationConfig is the rotation policy of the log file, the zero value of
+// each field means its default.
+type RotationConfig struct {
+	// RotationTime is the time between rotations, 24 hours by default
+	RotationTime time.Duration
+	// MaxAge is the max age of a rotated file before it gets purged, 7 days
+	// by default. It can not be set along with MaxCount.
+	MaxAge time.Duration
+	// MaxCount is the number of files kept before they get purged
+	MaxCount uint
+	// MaxSize is the max size in bytes of a file before it gets rotated,
+	// 512MB by default, a negative size turns the size rotation off.
+	MaxSize int64
+	// Pattern is the strftime pattern appended to the log file name to name
+	// the rotated files, `.%Y-%m-%d` by default.
+	Pattern string
+	// Clock decides when to rotate, the clock of the logger by default
+	Clock Clock
+	// Compress gzips the rotated files in background once they are no longer
+	// written, the compressed files count for MaxAge and MaxCount as well.
+	Compress bool
+}
+
+// DefaultRotation is the rotation policy used when none is given
+var DefaultRotation = RotationConfig{
+	RotationTime: DayHours * time.Hour,
+	MaxAge:       WeekHours * time.Hour,
+	MaxSize:      512 * 1024 * 1024,
+	Pattern:      ".%Y-%m-%d",
+}
+
+// withDefaults returns the config with the zero fields set to the defaults
+func (c RotationConfig) withDefaults() RotationConfig {
+	if c.RotationTime == 0 {
+		c.RotationTime = DefaultRotation.RotationTime
+	}
+	if c.MaxAge == 0 && c.MaxCount == 0 {
+		c.MaxAge = DefaultRotation.MaxAge
+	}
+	if c.MaxSize == 0 {
+		c.MaxSize = DefaultRotation.MaxSize
+	}
+	if c.Pattern == "" {
+		c.Pattern = DefaultRotation.Pattern
+	}
+	if c.Clock == nil {
+		c.Clock = systemClock{}
+	}
+	return c
+}
+
+// Validate checks the rotation policy
+func (c RotationConfig) Validate() error {
+	if c.RotationTime < 0 {
+		return fmt.Errorf("invalid rotation time %s", c.RotationTime)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("invalid max age %s", c.MaxAge)
+	}
+	if c.MaxAge > 0 && c.MaxCount > 0 {
+		return fmt.Errorf("max age and max count can not be both set")
+	}
+	if c.Pattern != "" && !strings.Contains(c.Pattern, "%") {
+		return fmt.Errorf("rotation pattern %q has no time conversion", c.Pattern)
+	}
+	if strings.ContainsAny(c.Pattern, `/\`) {
+		return fmt.Errorf("rotation pattern %q must not contain path separators", c.Pattern)
+	}
+	return nil
+}
+
+// rotateOptions returns the rotatelogs options of the rotation policy, the
+// rotated files are named by linkName with the pattern appended.
+func (c RotationConfig) rotateOptions(linkName string) []rotatelogs.Option {
+	opts := []rotatelogs.Option{
+		// create a new Option that sets the symbolic link name that gets linked to the current file name being used.
+		rotatelogs.WithLinkName(linkName),
+		// create a new Option that sets the time between rotation.
+		rotatelogs.WithRotationTime(c.RotationTime),
+		rotatelogs.WithClock(c.Clock),
+	}
+	switch {
+	case c.Compress:
+		// the compressor purges the files, rotatelogs falls back to its max
+		// age of 7 days on WithMaxAge(-1), so its purge is disabled by a max
+		// age no file reaches
+		opts = append(opts, rotatelogs.WithMaxAge(time.Duration(math.MaxInt64)))
+	case c.MaxCount > 0:
+		// create a new Option that sets the number of files should be kept before it gets purged from the file system.
+		opts = append(opts, rotatelogs.WithRotationCount(c.MaxCount))
+	default:
+		// creates a new Option that sets the max age of a log file before it gets purged from the file system.
+		opts = append(opts, rotatelogs.WithMaxAge(c.MaxAge))
+	}
+	if c.MaxSize > 0 {
+		opts = append(opts, rotatelogs.WithRotationSize(c.MaxSize))
+	}
+	return opts
+}
@@ -0,0 +1,102 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
+)
+
+func TestRotationTime(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	logFile := filepath.Join(t.TempDir(), "rotate.log")
+	logger, err := log.New(
+		log.WithLogFile(logFile),
+		log.WithFormatter(log.OutputFile, &log.Formatter{LogFormat: "%msg%\n"}),
+		log.WithRotation(log.RotationConfig{RotationTime: time.Hour, MaxCount: 2, Pattern: ".%Y%m%d%H", Clock: clock}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		logger.Info(msg)
+		clock.Add(time.Hour)
+	}
+
+	if got := log.ReadLogFile(t, logFile+".2021030911"); got != "second\n" {
+		t.Errorf("unexpected rotated file content %q", got)
+	}
+	// the link always points to the current file
+	if got := log.ReadLogFile(t, logFile); got != "third\n" {
+		t.Errorf("unexpected current file content %q", got)
+	}
+
+	// the files beyond max count are purged in background
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(logFile + ".2021030910"); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the oldest file is not purged")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRotationSize(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))
+	logFile := filepath.Join(t.TempDir(), "rotate.log")
+	logger, err := log.New(
+		log.WithLogFile(logFile),
+		log.WithFormatter(log.OutputFile, &log.Formatter{LogFormat: "%msg%\n"}),
+		// the clock of the logger drives the rotation as well
+		log.WithClock(clock),
+		log.WithRotation(log.RotationConfig{MaxSize: 10}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("0123456789")
+	logger.Info("next")
+
+	if got := log.ReadLogFile(t, logFile+".2021-03-09"); got != "0123456789\n" {
+		t.Errorf("unexpected rotated file content %q", got)
+	}
+	if got := log.ReadLogFile(t, logFile+".2021-03-09.1"); got != "next\n" {
+		t.Errorf("unexpected current file content %q", got)
+	}
+}
+
+func TestRotationValidate(t *testing.T) {
+	for _, c := range []struct {
+		rotation log.RotationConfig
+		err      string
+	}{
+		{log.RotationConfig{RotationTime: -time.Hour}, "invalid rotation time"},
+		{log.RotationConfig{MaxAge: -time.Hour}, "invalid max age"},
+		{log.RotationConfig{MaxAge: time.Hour, MaxCount: 3}, "can not be both set"},
+		{log.RotationConfig{Pattern: ".log"}, "no time conversion"},
+		{log.RotationConfig{Pattern: "/%Y/%m/%d"}, "path separators"},
+	} {
+		err := c.rotation.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("Validate(%+v) = %v, want %q", c.rotation, err, c.err)
+		}
+
+		_, err = log.New(log.WithLogFile(filepath.Join(t.TempDir(), "rotate.log")), log.WithRotation(c.rotation))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("log.New with %+v = %v, want %q", c.rotation, err, c.err)
+		}
+	}
+
+	if err := log.DefaultRotation.Validate(); err != nil {
+		t.Errorf("log.DefaultRotation is invalid: %s", err)
+	}
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink receives every logged entry along with stderr and the log file, i.e. a
+// syslog daemon. The sinks implementing io.Closer are closed along with the
+// logger.
+type Sink interface {
+	// WriteEntry writes the entry, which must not be modified nor kept after
+	// returning. It is called by concurrent goroutines in sync mode.
+	WriteEntry(entry *logrus.Entry) error
+}
+
+// writeSinks writes the record into the sinks, the errors are reported into
+// stderr as logrus does for the hooks
+func (logger *SWLog) writeSinks(r *record) {
+	if len(logger.sinks) == 0 {
+		return
+	}
+
+	entry := logrus.NewEntry(logger.FileLogger).WithFields(r.fields).WithContext(r.ctx).WithTime(r.time)
+	entry.Level = r.level
+	entry.Message = r.message
+	for _, sink := range logger.sinks {
+		if err := sink.WriteEntry(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "write log entry into sink error: %s\n", err.Error())
+		}
+	}
+}
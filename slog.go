@@ -0,0 +1,142 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SlogHandler implements slog.Handler by logging the records into SWLog, the
+// attributes become the fields of the entries, and the attributes inside
+// groups are named by the group names joined with dots, i.e. `req.id`.
+type SlogHandler struct {
+	logger *SWLog
+	// fields added by WithAttrs
+	fields logrus.Fields
+	// prefix of the attribute names added by WithGroup
+	prefix string
+}
+
+// NewSlogHandler creates a slog.Handler logging into logger, i.e.
+// `slog.New(log.NewSlogHandler(log.SWLogger))`.
+func NewSlogHandler(logger *SWLog) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// SlogLevel converts the slog level into the log level, the levels above
+// slog.LevelError are logged in error level rather than exiting or panicking.
+func SlogLevel(level slog.Level) logrus.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return logrus.DebugLevel
+	case level < slog.LevelWarn:
+		return logrus.InfoLevel
+	case level < slog.LevelError:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// Enabled reports whether the logger logs in the level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.isLevelEnabled(SlogLevel(level))
+}
+
+// Handle logs the record with the calling info of the record
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.logger.isSetup {
+		return fmt.Errorf("log not setup")
+	}
+	level := SlogLevel(r.Level)
+	if !h.logger.isLevelEnabled(level) {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// the fields of the request-scoped logger of ctx come first
+	ctxData := FromContext(ctx).Data
+	fields := make(logrus.Fields, len(ctxData)+len(h.fields)+r.NumAttrs())
+	for k, v := range ctxData {
+		fields[k] = v
+	}
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	var filename, funcname string
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		filename, funcname = callerNames(frame.File, frame.Line, frame.Function)
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = h.logger.clock.Now()
+	}
+	h.logger.output(&record{
+		level:   level,
+		fields:  h.logger.contextFields(ctx, fields),
+		ctx:     withCaller(ctx, filename, funcname),
+		time:    t,
+		message: r.Message,
+	})
+	return nil
+}
+
+// WithAttrs returns a handler adding the attributes as fields
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(logrus.Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, attr := range attrs {
+		addSlogAttr(fields, h.prefix, attr)
+	}
+	return &SlogHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+// WithGroup returns a handler naming the following attributes inside the group
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr adds the attribute into fields, the attributes of a group are
+// flattened
+func addSlogAttr(fields logrus.Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		// the attributes of a group without name are inlined
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addSlogAttr(fields, groupPrefix, groupAttr)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	fields[prefix+attr.Key] = attr.Value.Any()
+}
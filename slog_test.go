@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newSlogTestLogger(t *testing.T, level logrus.Level) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := New(
+		WithLevel(level),
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &LogfmtFormatter{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return slog.New(NewSlogHandler(logger)), &buf
+}
+
+func TestSlogHandler(t *testing.T) {
+	logger, buf := newSlogTestLogger(t, logrus.DebugLevel)
+
+	logger = logger.With("app", "test").WithGroup("req").With("id", 7)
+	logger.Info("hello", "user", "alice", slog.Group("peer", "addr", "127.0.0.1"), slog.Group("", "inline", true),
+		slog.Duration("latency", 1500*time.Millisecond), "error", errors.New("boom"))
+
+	line := strings.TrimSuffix(buf.String(), "\n") + " "
+	for _, field := range []string{
+		"level=info ", "func=TestSlogHandler ", "/slog_test.go:32 ", "msg=hello ", "app=test ", "req.id=7 ",
+		"req.user=alice ", "req.peer.addr=127.0.0.1 ", "req.inline=true ", "req.latency=1.5s ", "req.error=boom ",
+	} {
+		if !strings.Contains(line, field) {
+			t.Errorf("%q is missing in %q", field, line)
+		}
+	}
+}
+
+func TestSlogHandlerLevel(t *testing.T) {
+	logger, buf := newSlogTestLogger(t, logrus.InfoLevel)
+
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug level is enabled")
+	}
+	logger.Debug("filtered")
+	logger.Warn("warning")
+	logger.Log(context.Background(), slog.LevelError+4, "critical")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "level=warning") || !strings.Contains(lines[1], "level=error") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+
+	for level, want := range map[slog.Level]logrus.Level{
+		slog.LevelDebug - 4: logrus.DebugLevel,
+		slog.LevelDebug:     logrus.DebugLevel,
+		slog.LevelInfo:      logrus.InfoLevel,
+		slog.LevelInfo + 2:  logrus.InfoLevel,
+		slog.LevelWarn:      logrus.WarnLevel,
+		slog.LevelError:     logrus.ErrorLevel,
+		slog.LevelError + 4: logrus.ErrorLevel,
+	} {
+		if got := SlogLevel(level); got != want {
+			t.Errorf("SlogLevel(%s) = %s, want %s", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StdLogPrefixKey is the field name of the prefix of the standard library
+// loggers
+const StdLogPrefixKey = "prefix"
+
+// stdLogWriter logs the lines written by a standard library logger, the
+// prefix, date, time and file header added according to its flags is parsed
+// out of the lines.
+type stdLogWriter struct {
+	logger *SWLog
+	level  logrus.Level
+	std    *stdlog.Logger
+}
+
+// StdLogger returns a standard library logger logging into logger in the level,
+// i.e. for `http.Server.ErrorLog`. The prefix and the flags may be changed
+// later, the header they add is removed from the messages.
+func (logger *SWLog) StdLogger(level logrus.Level) *stdlog.Logger {
+	w := &stdLogWriter{logger: logger, level: level}
+	w.std = stdlog.New(w, "", 0)
+	return w.std
+}
+
+// RedirectStdLog redirects the output of the standard library log package into
+// logger in the level, the returned function restores the previous output.
+func (logger *SWLog) RedirectStdLog(level logrus.Level) (restore func()) {
+	std := stdlog.Default()
+	previous := std.Writer()
+	std.SetOutput(&stdLogWriter{logger: logger, level: level, std: std})
+	return func() {
+		std.SetOutput(previous)
+	}
+}
+
+// Write logs a line written by the standard library logger
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.logger.isSetup {
+		return 0, fmt.Errorf("log not setup")
+	}
+	if !w.logger.isLevelEnabled(w.level) {
+		return len(p), nil
+	}
+
+	prefix, message := parseStdLogLine(strings.TrimSuffix(string(p), "\n"), w.std.Prefix(), w.std.Flags())
+	var fields logrus.Fields
+	if prefix = strings.TrimSpace(prefix); prefix != "" {
+		fields = logrus.Fields{StdLogPrefixKey: prefix}
+	}
+	filename, funcname := stdLogCaller()
+	w.logger.log(context.Background(), w.level, fields, filename, funcname, message)
+	return len(p), nil
+}
+
+// parseStdLogLine splits the line formatted by a standard library logger with
+// the prefix and the flags into the prefix and the message, the line is kept as
+// the message when it does not start with the header.
+func parseStdLogLine(line string, prefix string, flags int) (string, string) {
+	message := line
+	if flags&stdlog.Lmsgprefix == 0 {
+		if !strings.HasPrefix(message, prefix) {
+			return "", line
+		}
+		message = message[len(prefix):]
+	}
+
+	var headerLen int
+	if flags&stdlog.Ldate != 0 {
+		headerLen += len("2006/01/02 ")
+	}
+	if flags&(stdlog.Ltime|stdlog.Lmicroseconds) != 0 {
+		headerLen += len("15:04:05 ")
+		if flags&stdlog.Lmicroseconds != 0 {
+			headerLen += len(".000000")
+		}
+	}
+	if len(message) < headerLen {
+		return "", line
+	}
+	message = message[headerLen:]
+
+	if flags&(stdlog.Lshortfile|stdlog.Llongfile) != 0 {
+		i := strings.Index(message, ": ")
+		if i < 0 {
+			return "", line
+		}
+		message = message[i+len(": "):]
+	}
+
+	if flags&stdlog.Lmsgprefix != 0 {
+		if !strings.HasPrefix(message, prefix) {
+			return "", line
+		}
+		message = message[len(prefix):]
+	}
+	return prefix, message
+}
+
+// stdLogCaller returns the file name with line number and the function name of
+// the caller of the standard library logger, which is the first stack frame
+// outside the log and log/slog packages below them.
+func stdLogCaller() (filename string, funcname string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var first *runtime.Frame
+	inStdLog := false
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "log.") || strings.HasPrefix(frame.Function, "log/slog.") {
+			inStdLog = true
+		} else if inStdLog {
+			return callerNames(frame.File, frame.Line, frame.Function)
+		} else if first == nil {
+			first = &frame
+		}
+		if !more {
+			break
+		}
+	}
+	// the writer is not called by a standard library logger
+	if first == nil {
+		return "", ""
+	}
+	return callerNames(first.File, first.Line, first.Function)
+}
+
+// StdLogger returns a standard library logger logging into SWLogger in the level
+func StdLogger(level logrus.Level) *stdlog.Logger {
+	return SWLogger.StdLogger(level)
+}
+
+// RedirectStdLog redirects the output of the standard library log package into
+// SWLogger in the level, the returned function restores the previous output.
+func RedirectStdLog(level logrus.Level) (restore func()) {
+	return SWLogger.RedirectStdLog(level)
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newBufferTestLogger(t *testing.T) (*SWLog, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := New(
+		WithLevel(logrus.InfoLevel),
+		WithOutput(&buf),
+		WithFormatter(OutputFile, &LogfmtFormatter{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, &buf
+}
+
+func TestStdLogger(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+
+	std := logger.StdLogger(logrus.WarnLevel)
+	std.SetPrefix("[http] ")
+	std.SetFlags(stdlog.LstdFlags | stdlog.Lmicroseconds | stdlog.Lshortfile)
+	std.Printf("accept error: %s", "EOF")
+
+	line := strings.TrimSuffix(buf.String(), "\n") + " "
+	for _, field := range []string{
+		"level=warning ", "/stdlog_test.go:31 ", "func=TestStdLogger ", `msg="accept error: EOF" `, "prefix=[http] ",
+	} {
+		if !strings.Contains(line, field) {
+			t.Errorf("%q is missing in %q", field, line)
+		}
+	}
+
+	buf.Reset()
+	logger.StdLogger(logrus.DebugLevel).Print("filtered")
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+
+	restore := logger.RedirectStdLog(logrus.InfoLevel)
+	stdlog.Println("redirected")
+	restore()
+
+	line := buf.String()
+	if !strings.Contains(line, "func=TestRedirectStdLog ") || !strings.Contains(line, "msg=redirected") ||
+		strings.Contains(line, "prefix=") {
+		t.Fatalf("unexpected output %q", line)
+	}
+	if _, ok := stdlog.Writer().(*stdLogWriter); ok {
+		t.Fatal("the output is not restored")
+	}
+}
+
+func TestParseStdLogLine(t *testing.T) {
+	for _, c := range []struct {
+		line   string
+		prefix string
+		flags  int
+		want   string
+	}{
+		{"hello", "", 0, "hello"},
+		{"app: hello", "app: ", 0, "hello"},
+		{"app: 2021/03/09 10:16:18 hello", "app: ", stdlog.LstdFlags, "hello"},
+		{"2021/03/09 10:16:18.123456 main.go:12: app: hello", "app: ", stdlog.LstdFlags | stdlog.Lmicroseconds | stdlog.Lshortfile | stdlog.Lmsgprefix, "hello"},
+		{"10:16:18 /src/main.go:12: hello: world", "", stdlog.Ltime | stdlog.Llongfile, "hello: world"},
+		{"unexpected", "app: ", 0, "unexpected"},
+		{"short", "", stdlog.LstdFlags, "short"},
+	} {
+		if _, got := parseStdLogLine(c.line, c.prefix, c.flags); got != c.want {
+			t.Errorf("parseStdLogLine(%q, %q, %d) = %q, want %q", c.line, c.prefix, c.flags, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyslogFacility is the syslog facility of the messages
+type SyslogFacility int
+
+// syslog facilities, see RFC 5424
+const (
+	// SyslogKern is reserved for the kernel messages, it is the zero value of
+	// SyslogConfig.Facility meaning SyslogUser, so it can not be set
+	SyslogKern SyslogFacility = iota
+	SyslogUser
+	SyslogMail
+	SyslogDaemon
+	SyslogAuth
+	SyslogSyslog
+	SyslogLPR
+	SyslogNews
+	SyslogUUCP
+	SyslogCron
+	SyslogAuthPriv
+	SyslogFTP
+	_ // NTP
+	_ // log audit
+	_ // log alert
+	_ // clock daemon
+	SyslogLocal0
+	SyslogLocal1
+	SyslogLocal2
+	SyslogLocal3
+	SyslogLocal4
+	SyslogLocal5
+	SyslogLocal6
+	SyslogLocal7
+)
+
+// SyslogSeverity is the syslog severity of the messages
+type SyslogSeverity int
+
+// syslog severities, see RFC 5424
+const (
+	SyslogEmerg SyslogSeverity = iota
+	SyslogAlert
+	SyslogCrit
+	SyslogErr
+	SyslogWarning
+	SyslogNotice
+	SyslogInfo
+	SyslogDebug
+)
+
+// SyslogFormat is the format of the syslog messages
+type SyslogFormat int
+
+const (
+	// SyslogRFC5424 is the format of RFC 5424, the messages are framed by
+	// octet counting over stream connections
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 is the BSD format of RFC 3164, the messages are
+	// terminated by newlines over stream connections
+	SyslogRFC3164
+)
+
+// DefaultSyslogSeverities is the syslog severity of each log level
+var DefaultSyslogSeverities = map[logrus.Level]SyslogSeverity{
+	PanicLevel:        SyslogAlert,
+	FatalLevel:        SyslogCrit,
+	ErrorLevel:        SyslogErr,
+	WarnLevel:         SyslogWarning,
+	InfoLevel:         SyslogInfo,
+	DebugLevel:        SyslogDebug,
+	logrus.TraceLevel: SyslogDebug,
+}
+
+// syslogSockets are the unix sockets of the local syslog daemon
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig configures the syslog sink
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram", the local syslog daemon
+	// is used if both Network and Address are empty
+	Network string
+	// Address is the host:port or the unix socket path of the syslog daemon
+	Address string
+	// Facility of the messages, SyslogUser by default, SyslogKern is the zero
+	// value so it is replaced by SyslogUser as well
+	Facility SyslogFacility
+	// AppName is the app name or tag of the messages, the program name by
+	// default
+	AppName string
+	// Hostname of the messages, the local host name by default
+	Hostname string
+	// Format of the messages, SyslogRFC5424 by default
+	Format SyslogFormat
+	// Severities overrides the syslog severity of some log levels, see
+	// DefaultSyslogSeverities
+	Severities map[logrus.Level]SyslogSeverity
+	// Formatter formats the message part, the message followed by the caller
+	// and the fields in logfmt by default, i.e.
+	// `hello world caller=pkg/file.go:12 func=foo key=value`
+	Formatter logrus.Formatter
+	// DialTimeout of the connections, 5 seconds by default
+	DialTimeout time.Duration
+	// RetryInterval is the time to wait after a failed connection before
+	// connecting again, the entries fail without connecting in between, 1
+	// second by default
+	RetryInterval time.Duration
+}
+
+// SyslogSink is a Sink sending the entries to a syslog daemon, it reconnects
+// once the connection fails, at most once per retry interval.
+type SyslogSink struct {
+	config     SyslogConfig
+	severities map[logrus.Level]SyslogSeverity
+	pid        string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	network string
+	address string
+	// failed is the time of the last failed connection
+	failed time.Time
+}
+
+// NewSyslogSink connects to the syslog daemon, i.e.
+// `log.New(log.WithSink(sink))`
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	if config.Facility < SyslogKern || config.Facility > SyslogLocal7 {
+		return nil, fmt.Errorf("invalid syslog facility %d", config.Facility)
+	}
+	if config.Facility == SyslogKern {
+		// the zero value, see SyslogKern
+		config.Facility = SyslogUser
+	}
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = time.Second
+	}
+
+	severities, err := syslogSeverities(config.Severities)
+	if err != nil {
+		return nil, err
+	}
+	sink := &SyslogSink{
+		config:     config,
+		severities: severities,
+		pid:        strconv.Itoa(os.Getpid()),
+		network:    config.Network,
+		address:    config.Address,
+	}
+	if err := sink.connect(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// syslogSeverities returns DefaultSyslogSeverities with the overrides
+func syslogSeverities(overrides map[logrus.Level]SyslogSeverity) (map[logrus.Level]SyslogSeverity, error) {
+	severities := make(map[logrus.Level]SyslogSeverity, len(DefaultSyslogSeverities))
+	for level, severity := range DefaultSyslogSeverities {
+		severities[level] = severity
+	}
+	for level, severity := range overrides {
+		if severity < SyslogEmerg || severity > SyslogDebug {
+			return nil, fmt.Errorf("invalid syslog severity %d of level %s", severity, level)
+		}
+		severities[level] = severity
+	}
+	return severities, nil
+}
+
+// connect dials the syslog daemon, the local sockets are tried in turn
+func (sink *SyslogSink) connect() error {
+	if sink.network != "" || sink.address != "" {
+		conn, err := net.DialTimeout(sink.network, sink.address, sink.config.DialTimeout)
+		if err != nil {
+			return fmt.Errorf("connect syslog %s %s error: %s", sink.network, sink.address, err.Error())
+		}
+		sink.conn = conn
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, address := range syslogSockets {
+			conn, err := net.DialTimeout(network, address, sink.config.DialTimeout)
+			if err == nil {
+				// reconnect to the same socket
+				sink.network, sink.address, sink.conn = network, address, conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("connect local syslog error: no syslog socket in %s", strings.Join(syslogSockets, ", "))
+}
+
+// WriteEntry sends the entry, the message is sent once more over a new
+// connection if it fails. The entries fail right away until the retry interval
+// passes after a failed connection, so that a dead daemon does not stall the
+// logging for the dial timeout of every entry.
+func (sink *SyslogSink) WriteEntry(entry *logrus.Entry) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	// the framing depends on the network of the local socket
+	msg, err := sink.message(entry)
+	if err != nil {
+		return err
+	}
+	if sink.conn != nil {
+		if _, err = sink.conn.Write(msg); err == nil {
+			return nil
+		}
+		sink.conn.Close()
+		sink.conn = nil
+	}
+	if !sink.failed.IsZero() && time.Since(sink.failed) < sink.config.RetryInterval {
+		return fmt.Errorf("syslog is disconnected, retry in %s", sink.config.RetryInterval-time.Since(sink.failed))
+	}
+	if err := sink.connect(); err != nil {
+		sink.failed = time.Now()
+		return err
+	}
+	sink.failed = time.Time{}
+	if _, err = sink.conn.Write(msg); err != nil {
+		sink.conn.Close()
+		sink.conn = nil
+		return fmt.Errorf("write syslog error: %s", err.Error())
+	}
+	return nil
+}
+
+// Close closes the connection
+func (sink *SyslogSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.conn == nil {
+		return nil
+	}
+	err := sink.conn.Close()
+	sink.conn = nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// message builds the framed syslog message of the entry
+func (sink *SyslogSink) message(entry *logrus.Entry) ([]byte, error) {
+	content, err := sink.content(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	severity, ok := sink.severities[entry.Level]
+	if !ok {
+		severity = SyslogDebug
+	}
+	priority := int(sink.config.Facility)<<3 | int(severity)
+	local := strings.HasPrefix(sink.network, "unix")
+
+	buf := &bytes.Buffer{}
+	switch sink.config.Format {
+	case SyslogRFC3164:
+		// the local daemons add the host name themselves
+		fmt.Fprintf(buf, "<%d>%s ", priority, entry.Time.Format(time.Stamp))
+		if !local {
+			buf.WriteString(syslogHeaderField(sink.config.Hostname, 255))
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(buf, "%s[%s]: %s", syslogHeaderField(sink.config.AppName, 32), sink.pid, content)
+	default:
+		fmt.Fprintf(buf, "<%d>1 %s %s %s %s - - %s", priority,
+			entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogHeaderField(sink.config.Hostname, 255),
+			syslogHeaderField(sink.config.AppName, 48),
+			sink.pid,
+			content)
+	}
+
+	// datagrams carry a single message, stream messages need framing
+	switch sink.network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return buf.Bytes(), nil
+	}
+	if sink.config.Format == SyslogRFC3164 {
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	return append([]byte(strconv.Itoa(buf.Len())+" "), buf.Bytes()...), nil
+}
+
+// content returns the message part of the entry without newline suffix
+func (sink *SyslogSink) content(entry *logrus.Entry) (string, error) {
+	if sink.config.Formatter != nil {
+		b, err := sink.config.Formatter.Format(entry)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\n"), nil
+	}
+
+	filename, funcname := EntryCaller(entry)
+	buf := &bytes.Buffer{}
+	writeLogfmtPair(buf, JSONKeyCaller, filename)
+	writeLogfmtPair(buf, JSONKeyFunc, funcname)
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtPair(buf, k, logfmtValue(entry.Data[k]))
+	}
+	return strings.TrimRight(entry.Message, "\n") + " " + buf.String(), nil
+}
+
+// syslogHeaderField returns the printable ASCII characters of the header field
+// up to max, "-" if empty
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+package log_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edony-ink/log"
+	"github.com/edony-ink/log/logtest"
+	"github.com/sirupsen/logrus"
+)
+
+func newSyslogTestLogger(t *testing.T, config log.SyslogConfig) *log.SWLog {
+	sink, err := log.NewSyslogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := log.New(
+		log.WithOutput(io.Discard),
+		log.WithSink(sink),
+		log.WithClock(logtest.NewFakeClock(time.Date(2021, 3, 9, 10, 16, 18, 0, time.UTC))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		logger.Close()
+	})
+	return logger
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := newSyslogTestLogger(t, log.SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: log.SyslogLocal0,
+		AppName:  "my app",
+		Hostname: "host",
+	})
+	logger.WithField("user", "alice").Warn("hello")
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	prefix := "<132>1 2021-03-09T10:16:18.000000Z host myapp " + strconv.Itoa(os.Getpid()) + " - - hello caller="
+	suffix := "/syslog_test.go:52 func=TestSyslogSinkUDP user=alice"
+	if !strings.HasPrefix(msg, prefix) || !strings.HasSuffix(msg, suffix) {
+		t.Fatalf("got message %q, want %q...%q", msg, prefix, suffix)
+	}
+}
+
+func TestSyslogSinkTCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	messages := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// read a single octet counted message per connection
+			r := bufio.NewReader(conn)
+			size, err := r.ReadString(' ')
+			if err == nil {
+				n, _ := strconv.Atoi(strings.TrimSuffix(size, " "))
+				msg := make([]byte, n)
+				if _, err := io.ReadFull(r, msg); err == nil {
+					messages <- string(msg)
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	logger := newSyslogTestLogger(t, log.SyslogConfig{Network: "tcp", Address: ln.Addr().String(), AppName: "app"})
+	logger.Error("first")
+	if msg := <-messages; !strings.HasPrefix(msg, "<11>1 ") || !strings.Contains(msg, " app ") ||
+		!strings.Contains(msg, " - - first caller=") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+
+	// the server closed the first connection, the entries are sent over a
+	// new one once the writes fail
+	deadline := time.After(5 * time.Second)
+	for i := 0; ; i++ {
+		logger.Errorf("retry %d", i)
+		select {
+		case msg := <-messages:
+			if !strings.Contains(msg, " - - retry ") {
+				t.Fatalf("unexpected message %q", msg)
+			}
+			return
+		case <-deadline:
+			t.Fatal("no reconnection")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestSyslogSinkLocal(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "log")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	sockets := *log.SyslogSockets
+	*log.SyslogSockets = []string{filepath.Join(t.TempDir(), "missing"), socket}
+	defer func() {
+		*log.SyslogSockets = sockets
+	}()
+
+	logger := newSyslogTestLogger(t, log.SyslogConfig{
+		AppName:    "app",
+		Format:     log.SyslogRFC3164,
+		Severities: map[logrus.Level]log.SyslogSeverity{log.InfoLevel: log.SyslogNotice},
+		Formatter:  &log.Formatter{LogFormat: "%func%|%msg%\n"},
+	})
+	logger.Info("hello")
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<13>Mar  9 10:16:18 app[" + strconv.Itoa(os.Getpid()) + "]: TestSyslogSinkLocal|hello"
+	if got := string(buf[:n]); got != want {
+		t.Fatalf("got message %q, want %q", got, want)
+	}
+}
+
+func TestNewSyslogSinkError(t *testing.T) {
+	if _, err := log.NewSyslogSink(log.SyslogConfig{Facility: log.SyslogLocal7 + 1}); err == nil {
+		t.Error("no error for an invalid facility")
+	}
+	if _, err := log.NewSyslogSink(log.SyslogConfig{Network: "udp", Address: "127.0.0.1:1", Severities: map[logrus.Level]log.SyslogSeverity{log.InfoLevel: 8}}); err == nil {
+		t.Error("no error for an invalid severity")
+	}
+	if _, err := log.NewSyslogSink(log.SyslogConfig{Network: "unix", Address: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("no error for a missing socket")
+	}
+}
+
+func TestSyslogSinkRetryInterval(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	sink, err := log.NewSyslogSink(log.SyslogConfig{Network: "tcp", Address: address, RetryInterval: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// the daemon is down
+	ln.Close()
+	sink.Close()
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "hello"
+	if err := sink.WriteEntry(entry); err == nil {
+		t.Fatal("no error without the daemon")
+	}
+
+	// the daemon is up again, but the sink waits for the retry interval
+	ln, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("listen to %s again: %s", address, err)
+	}
+	defer ln.Close()
+	if err := sink.WriteEntry(entry); err == nil || !strings.Contains(err.Error(), "retry in") {
+		t.Fatalf("got error %v, want retrying later", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if err := sink.WriteEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxWriterLineSize is the size of the partial line after which it is logged
+// without waiting for the newline
+const maxWriterLineSize = 64 * 1024
+
+// levelWriter logs each line written into it as an entry
+type levelWriter struct {
+	logger *SWLog
+	level  logrus.Level
+	// calling info of Writer, the lines are usually written by other
+	// goroutines such as the ones copying the output of a command
+	filename string
+	funcname string
+
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+}
+
+// Writer returns a writer logging each line written into it as an entry in the
+// level, i.e. for `exec.Cmd.Stdout`. The partial lines are buffered until the
+// newline or Close, and the entries have the calling info of Writer.
+func (logger *SWLog) Writer(level logrus.Level) io.WriteCloser {
+	filename, funcname := caller(logger.skip)
+	return logger.writer(level, filename, funcname)
+}
+
+func (logger *SWLog) writer(level logrus.Level, filename string, funcname string) io.WriteCloser {
+	return &levelWriter{logger: logger, level: level, filename: filename, funcname: funcname}
+}
+
+// Write logs the complete lines of p and buffers the trailing partial line
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= maxWriterLineSize {
+				w.logLine(w.buf)
+				w.buf = w.buf[:0]
+			}
+			break
+		}
+
+		line := p[:i]
+		if len(w.buf) > 0 {
+			line = append(w.buf, line...)
+			w.buf = w.buf[:0]
+		}
+		w.logLine(line)
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close logs the leftover partial line, the writes after Close fail
+func (w *levelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine logs the line without the trailing carriage return, the empty lines
+// are skipped
+func (w *levelWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return
+	}
+	w.logger.log(context.Background(), w.level, nil, w.filename, w.funcname, string(line))
+}
+
+// Writer returns a writer logging each line written into it as an entry of
+// SWLogger in the level
+func Writer(level logrus.Level) io.WriteCloser {
+	filename, funcname := caller(SWLogger.skip)
+	return SWLogger.writer(level, filename, funcname)
+}
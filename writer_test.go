@@ -0,0 +1,74 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWriter(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+
+	w := logger.Writer(logrus.WarnLevel)
+	for _, s := range []string{"first li", "ne\nsecond line\r\n\n", "third", " line\npartial"} {
+		if n, err := fmt.Fprint(w, s); err != nil || n != len(s) {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !strings.Contains(line, "level=warning ") || !strings.Contains(line, "/writer_test.go:17 ") ||
+			!strings.Contains(line, "func=TestWriter ") {
+			t.Errorf("unexpected line %q", line)
+		}
+		messages = append(messages, line[strings.Index(line, "msg="):])
+	}
+	want := []string{`msg="first line"`, `msg="second line"`, `msg="third line"`}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Fatalf("got messages %q, want %q", messages, want)
+	}
+
+	buf.Reset()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "msg=partial") {
+		t.Fatalf("the partial line is not flushed, got %q", buf.String())
+	}
+	if _, err := w.Write([]byte("closed\n")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Write after Close returned %v", err)
+	}
+}
+
+func TestWriterLongLine(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+
+	w := logger.Writer(logrus.InfoLevel)
+	w.Write([]byte(strings.Repeat("x", maxWriterLineSize)))
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("the long line is not logged, got %d bytes", buf.Len())
+	}
+}
+
+func TestWriterCommand(t *testing.T) {
+	logger, buf := newBufferTestLogger(t)
+
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2")
+	w := logger.Writer(logrus.InfoLevel)
+	defer w.Close()
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		t.Skipf("run sh error: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), "msg=out") || !strings.Contains(buf.String(), "msg=err") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}